@@ -0,0 +1,96 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// runAtlantis implements the atlantis subcommand, meant to run as a
+// custom workflow step. The files to check come from Atlantis' custom
+// workflow environment: ATLANTIS_CHANGED_FILES when a pre-workflow hook
+// exports the list explicitly, otherwise git against the merge base
+// named by BASE_BRANCH_NAME. Output is a markdown body sized for an
+// Atlantis PR comment, and the exit status follows check mode.
+func runAtlantis(args []string) int {
+	fs := flag.NewFlagSet("atlantis", flag.ExitOnError)
+	write := fs.Bool("write", false, "rewrite the offending files as well as reporting them")
+	fs.Parse(args)
+
+	var err error
+	cfg, err = loadConfig("")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "barry: %s\n", err)
+		return 1
+	}
+
+	type finding struct {
+		path string
+		diff string
+	}
+	var findings []finding
+	for _, path := range atlantisChangedFiles() {
+		if !hasSupportedExt(path) {
+			continue
+		}
+		src, err := os.ReadFile(path)
+		if err != nil {
+			continue // deleted in this PR
+		}
+		out, err := formatSource(path, src)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "barry: %s: %s\n", path, err)
+			continue
+		}
+		if bytes.Equal(src, out) {
+			continue
+		}
+		text, err := diffBytes(path, src, out)
+		if err != nil {
+			text = "(diff unavailable)"
+		}
+		findings = append(findings, finding{path: path, diff: text})
+		if *write {
+			os.WriteFile(path, out, 0644)
+		}
+	}
+	if len(findings) == 0 {
+		fmt.Println("All Terraform files are formatted.")
+		return 0
+	}
+	fmt.Printf("#### barry found %d unformatted file(s)\n\n", len(findings))
+	for _, f := range findings {
+		fmt.Printf("<details><summary><code>%s</code></summary>\n\n```diff\n%s```\n</details>\n\n", f.path, f.diff)
+	}
+	fmt.Println("Run `barry` locally, or let Atlantis apply the fixes if this workflow writes them.")
+	if *write {
+		return 0
+	}
+	return 2
+}
+
+// atlantisChangedFiles resolves the PR's changed files from the
+// environment Atlantis provides to custom workflow steps.
+func atlantisChangedFiles() []string {
+	if v := os.Getenv("ATLANTIS_CHANGED_FILES"); v != "" {
+		return strings.Fields(v)
+	}
+	base := os.Getenv("BASE_BRANCH_NAME")
+	if base == "" {
+		base = "main"
+	}
+	out, err := exec.Command("git", "diff", "--name-only", "origin/"+base+"...HEAD").Output()
+	if err != nil {
+		return nil
+	}
+	var files []string
+	for _, line := range strings.Split(string(out), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			files = append(files, line)
+		}
+	}
+	return files
+}
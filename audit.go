@@ -0,0 +1,196 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// runAuditModules implements the audit-modules subcommand: it downloads
+// the remote module sources a configuration references into a temp
+// directory and checks them read-only, reporting upstream modules that
+// do not meet the formatting standard. Nothing outside the temp
+// directory is ever written.
+func runAuditModules(args []string) int {
+	fs := flag.NewFlagSet("audit-modules", flag.ExitOnError)
+	configFile := fs.String("config", "", "path to a config file (defaults to .barry.hcl if present)")
+	fs.Parse(args)
+
+	var err error
+	cfg, err = loadConfig(*configFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "barry: %s\n", err)
+		return 1
+	}
+	targets := fs.Args()
+	if len(targets) == 0 {
+		targets = []string{"."}
+	}
+	sources := remoteModuleSources(targets)
+	if len(sources) == 0 {
+		fmt.Println("no remote module sources found")
+		return 0
+	}
+	base, err := os.MkdirTemp("", "barry-audit-")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "barry: %s\n", err)
+		return 1
+	}
+	defer os.RemoveAll(base)
+
+	exit := 0
+	for i, source := range sources {
+		dir := filepath.Join(base, fmt.Sprintf("mod%d", i))
+		if err := fetchModule(source, dir); err != nil {
+			fmt.Fprintf(os.Stderr, "barry: %s: %s\n", source, err)
+			exit = 1
+			continue
+		}
+		unformatted, err := countUnformatted(dir)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "barry: %s: %s\n", source, err)
+			exit = 1
+			continue
+		}
+		if unformatted == 0 {
+			fmt.Printf("%s: formatted\n", source)
+		} else {
+			fmt.Printf("%s: %d unformatted file(s)\n", source, unformatted)
+			if exit == 0 {
+				exit = 2
+			}
+		}
+	}
+	return exit
+}
+
+// remoteModuleSources gathers the distinct non-local module sources
+// referenced beneath the targets.
+func remoteModuleSources(targets []string) []string {
+	seen := make(map[string]bool)
+	for _, target := range targets {
+		files, err := lintTargetFiles(target)
+		if err != nil {
+			continue
+		}
+		for _, file := range files {
+			if !strings.HasSuffix(file, ".tf") {
+				continue
+			}
+			pf, err := parseSyntaxFile(file)
+			if err != nil || pf == nil {
+				continue
+			}
+			for _, block := range pf.body.Blocks {
+				if block.Type != "module" {
+					continue
+				}
+				attr, ok := block.Body.Attributes["source"]
+				if !ok {
+					continue
+				}
+				val, diags := attr.Expr.Value(nil)
+				if diags.HasErrors() || !val.IsKnown() || val.Type().FriendlyName() != "string" {
+					continue
+				}
+				source := val.AsString()
+				if strings.HasPrefix(source, "./") || strings.HasPrefix(source, "../") {
+					continue
+				}
+				seen[source] = true
+			}
+		}
+	}
+	sources := make([]string, 0, len(seen))
+	for source := range seen {
+		sources = append(sources, source)
+	}
+	sort.Strings(sources)
+	return sources
+}
+
+// fetchModule materializes a remote module source into dest via a
+// shallow git clone, resolving registry addresses through the registry
+// download API first.
+func fetchModule(source, dest string) error {
+	url, ref, err := resolveModuleSource(source, 0)
+	if err != nil {
+		return err
+	}
+	args := []string{"clone", "--depth", "1"}
+	if ref != "" {
+		args = append(args, "--branch", ref)
+	}
+	args = append(args, url, dest)
+	cmd := exec.Command("git", args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("git clone: %s (%s)", err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
+
+// resolveModuleSource turns a Terraform module source string into a
+// cloneable git URL plus optional ref.
+func resolveModuleSource(source string, depth int) (url, ref string, err error) {
+	if depth > 3 {
+		return "", "", fmt.Errorf("module source %q: too many indirections", source)
+	}
+	if i := strings.Index(source, "?ref="); i >= 0 {
+		ref = source[i+len("?ref="):]
+		source = source[:i]
+	}
+	switch {
+	case strings.HasPrefix(source, "git::"):
+		return strings.TrimPrefix(source, "git::"), ref, nil
+	case strings.HasPrefix(source, "github.com/"):
+		return "https://" + source + ".git", ref, nil
+	case strings.HasPrefix(source, "https://"), strings.HasPrefix(source, "ssh://"), strings.HasPrefix(source, "git@"):
+		return source, ref, nil
+	}
+	// namespace/name/provider is a registry address.
+	if parts := strings.Split(source, "/"); len(parts) == 3 && !strings.Contains(parts[0], ".") {
+		resp, err := http.Get(fmt.Sprintf("https://registry.terraform.io/v1/modules/%s/download", source))
+		if err != nil {
+			return "", "", err
+		}
+		defer resp.Body.Close()
+		location := resp.Header.Get("X-Terraform-Get")
+		if location == "" {
+			return "", "", fmt.Errorf("registry gave no download location for %q", source)
+		}
+		return resolveModuleSource(location, depth+1)
+	}
+	return "", "", fmt.Errorf("unsupported module source %q", source)
+}
+
+// countUnformatted checks every supported file beneath dir without
+// writing anything.
+func countUnformatted(dir string) (int, error) {
+	files, err := lintTargetFiles(dir)
+	if err != nil {
+		return 0, err
+	}
+	count := 0
+	for _, file := range files {
+		src, err := os.ReadFile(file)
+		if err != nil {
+			continue
+		}
+		out, err := formatSource(file, src)
+		if err != nil {
+			continue // unparseable upstream files are their own problem
+		}
+		if !bytes.Equal(src, out) {
+			count++
+		}
+	}
+	return count, nil
+}
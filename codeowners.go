@@ -0,0 +1,97 @@
+package main
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// codeownersRule is one pattern line from a CODEOWNERS file.
+type codeownersRule struct {
+	pattern string
+	owners  []string
+}
+
+// loadCodeowners reads the repository's CODEOWNERS file from its
+// conventional locations. Later rules win, matching GitHub's
+// semantics, so the rules keep file order.
+func loadCodeowners() []codeownersRule {
+	var rules []codeownersRule
+	for _, location := range []string{"CODEOWNERS", ".github/CODEOWNERS", "docs/CODEOWNERS"} {
+		src, err := os.ReadFile(location)
+		if err != nil {
+			continue
+		}
+		for _, line := range strings.Split(string(src), "\n") {
+			line = strings.TrimSpace(line)
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+			fields := strings.Fields(line)
+			if len(fields) < 2 {
+				continue
+			}
+			rules = append(rules, codeownersRule{pattern: fields[0], owners: fields[1:]})
+		}
+		break
+	}
+	return rules
+}
+
+// fileOwners resolves a path against the rules; the last matching
+// pattern wins. The matcher covers the common CODEOWNERS shapes:
+// anchored paths, directory prefixes and glob basenames.
+func fileOwners(rules []codeownersRule, filePath string) []string {
+	filePath = strings.TrimPrefix(filepath.ToSlash(filePath), "./")
+	var owners []string
+	for _, rule := range rules {
+		if codeownersMatch(rule.pattern, filePath) {
+			owners = rule.owners
+		}
+	}
+	return owners
+}
+
+func codeownersMatch(pattern, filePath string) bool {
+	pattern = strings.TrimPrefix(pattern, "/")
+	switch {
+	case strings.HasSuffix(pattern, "/"):
+		return strings.HasPrefix(filePath, pattern)
+	case strings.Contains(pattern, "*"):
+		if ok, err := path.Match(pattern, filePath); err == nil && ok {
+			return true
+		}
+		ok, err := path.Match(pattern, path.Base(filePath))
+		return err == nil && ok
+	default:
+		return filePath == pattern || strings.HasPrefix(filePath, pattern+"/")
+	}
+}
+
+// ownersBreakdown groups a report's unformatted files by owning team,
+// so a central platform team can route cleanup to the right owners.
+func ownersBreakdown(rep *runReport) map[string][]string {
+	rules := loadCodeowners()
+	if len(rules) == 0 {
+		return nil
+	}
+	grouped := make(map[string][]string)
+	for _, res := range rep.results {
+		if !res.Changed {
+			continue
+		}
+		owners := fileOwners(rules, res.Path)
+		if len(owners) == 0 {
+			owners = []string{"(unowned)"}
+		}
+		for _, owner := range owners {
+			grouped[owner] = append(grouped[owner], res.Path)
+		}
+	}
+	for _, files := range grouped {
+		sort.Strings(files)
+	}
+	return grouped
+}
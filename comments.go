@@ -0,0 +1,151 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	// commentLineRe matches a full-line # comment, capturing its
+	// indentation and text. Trailing comments after code never match.
+	commentLineRe = regexp.MustCompile(`^(\s*)#\s?(.*)$`)
+	// bulletRe matches list markers that start a new paragraph and hang
+	// their continuation lines.
+	bulletRe = regexp.MustCompile(`^([-*]|\d+[.)])\s+`)
+)
+
+// normalizeCommentSpacing applies the comment_spacing rule: a single
+// space after # (so #foo becomes # foo), a single space between code
+// and a trailing comment, and - when collapse_banners is also enabled -
+// ##-style banners reduced to a single #. Shebang-like #! prefixes,
+// bare # lines and heredoc body lines are left alone.
+func normalizeCommentSpacing(lines []renderLine) []renderLine {
+	banners := ruleEnabled("collapse_banners")
+	for i, line := range lines {
+		if line.protected {
+			continue
+		}
+		idx := commentStart(line.text)
+		if idx < 0 {
+			continue
+		}
+		before, comment := line.text[:idx], line.text[idx:]
+		if banners {
+			trimmed := strings.TrimLeft(comment, "#")
+			if len(comment)-len(trimmed) > 1 {
+				comment = "#" + trimmed
+			}
+		}
+		if len(comment) > 1 && comment[1] != ' ' && comment[1] != '#' && comment[1] != '!' {
+			comment = "# " + comment[1:]
+		}
+		if trimmedBefore := strings.TrimRight(before, " \t"); trimmedBefore != "" {
+			before = trimmedBefore + " "
+		}
+		lines[i].text = before + comment
+	}
+	return lines
+}
+
+// commentStart finds the byte offset of a # comment on the line, or -1,
+// ignoring # characters inside quoted strings.
+func commentStart(line string) int {
+	inString := false
+	for i := 0; i < len(line); i++ {
+		switch line[i] {
+		case '"':
+			if i == 0 || line[i-1] != '\\' {
+				inString = !inString
+			}
+		case '#':
+			if !inString {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// reflowComments re-wraps consecutive full-line # comment paragraphs at
+// the given width. Blank comment lines separate paragraphs, bullets
+// start hanging paragraphs, and lines carrying barry: directives or
+// living inside heredoc bodies are never rewrapped.
+func reflowComments(lines []renderLine, width int) []renderLine {
+	var out []renderLine
+	for i := 0; i < len(lines); {
+		line := lines[i]
+		m := commentLineRe.FindStringSubmatch(line.text)
+		if line.protected || m == nil || strings.Contains(line.text, "barry:") {
+			out = append(out, line)
+			i++
+			continue
+		}
+		indent := m[1]
+		var run []string
+		for i < len(lines) && !lines[i].protected {
+			mm := commentLineRe.FindStringSubmatch(lines[i].text)
+			if mm == nil || mm[1] != indent || strings.Contains(lines[i].text, "barry:") {
+				break
+			}
+			run = append(run, mm[2])
+			i++
+		}
+		for _, text := range reflowCommentRun(indent, run, width) {
+			out = append(out, renderLine{text: text})
+		}
+	}
+	return out
+}
+
+// reflowCommentRun rebuilds one run of same-indent comment lines.
+func reflowCommentRun(indent string, texts []string, width int) []string {
+	var out []string
+	var words []string
+	paraPrefix, contPrefix := "", ""
+	flush := func() {
+		if len(words) == 0 {
+			return
+		}
+		prefix := indent + "# "
+		budget := width - len(prefix)
+		if budget < 20 {
+			budget = 20
+		}
+		line := paraPrefix
+		atStart := true
+		for _, word := range words {
+			candidate := line + word
+			if !atStart {
+				candidate = line + " " + word
+			}
+			if len(candidate) > budget && !atStart {
+				out = append(out, strings.TrimRight(prefix+line, " "))
+				line = contPrefix + word
+			} else {
+				line = candidate
+			}
+			atStart = false
+		}
+		out = append(out, strings.TrimRight(prefix+line, " "))
+		words = nil
+		paraPrefix, contPrefix = "", ""
+	}
+	for _, text := range texts {
+		trimmed := strings.TrimSpace(text)
+		if trimmed == "" {
+			flush()
+			out = append(out, indent+"#")
+			continue
+		}
+		if b := bulletRe.FindStringSubmatch(text); b != nil {
+			flush()
+			paraPrefix = b[0]
+			contPrefix = strings.Repeat(" ", len(b[0]))
+			words = strings.Fields(text[len(b[0]):])
+			continue
+		}
+		words = append(words, strings.Fields(text)...)
+	}
+	flush()
+	return out
+}
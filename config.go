@@ -0,0 +1,235 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2/gohcl"
+	"github.com/hashicorp/hcl/v2/hclparse"
+)
+
+// configFileName is the config file barry looks for in the working
+// directory when no -config flag is given.
+const configFileName = ".barry.hcl"
+
+// Config holds barry's tunable behaviour, loaded from a .barry.hcl file
+// with zero values falling back to the defaults below.
+type Config struct {
+	// MetaArgsPlacement controls whether meta-arguments form the first
+	// attribute group ("top", the default) or the last group ("bottom").
+	MetaArgsPlacement string `hcl:"meta_args_placement,optional"`
+
+	// StyleVersion pins the rule-default generation; when barry's
+	// defaults evolve, a pinned repo keeps the old behaviour until it
+	// runs barry migrate-style.
+	StyleVersion string `hcl:"style_version,optional"`
+
+	// LifecycleLast moves lifecycle blocks and the depends_on
+	// meta-argument to the very end of a block, as some style guides
+	// prefer, regardless of MetaArgsPlacement.
+	LifecycleLast bool `hcl:"lifecycle_last,optional"`
+
+	// EditorConfig controls whether .editorconfig conventions
+	// (indent_size, end_of_line, insert_final_newline, charset) are
+	// applied to output; unset means enabled.
+	EditorConfig *bool `hcl:"editorconfig,optional"`
+
+	// MaxLineLength is the column at which long attribute expressions
+	// are wrapped across lines. Zero (the default) disables wrapping.
+	MaxLineLength int `hcl:"max_line_length,optional"`
+
+	// MaxBlankLines is how many consecutive blank lines survive
+	// formatting, for repos that use double blank lines to separate
+	// major sections. The minimum (and default) is 1.
+	MaxBlankLines int `hcl:"max_blank_lines,optional"`
+
+	// Header is a banner (license or copyright text, one line per
+	// line, without # markers) enforced at the top of every .tf file.
+	Header string `hcl:"header,optional"`
+
+	// SortAttributes lists attribute names (tags, labels, ...) whose
+	// map literal values are always sorted by key, without needing a
+	// # barry:sort directive on each occurrence.
+	SortAttributes []string `hcl:"sort_attributes,optional"`
+
+	// PluginDir is a directory of barry-* executables implementing the
+	// exec+JSON plugin protocol; they run alongside the built-in rules.
+	PluginDir string `hcl:"plugin_dir,optional"`
+
+	// PolicyDir is a directory of *.rego policies evaluated by lint
+	// against the parsed block structure via data.barry.deny.
+	PolicyDir string `hcl:"policy_dir,optional"`
+
+	// NamingPattern is the regular expression resource, variable and
+	// output names must match under the naming_convention lint rule.
+	NamingPattern string `hcl:"naming_pattern,optional"`
+
+	// FileTypes maps glob patterns to per-file dialect and rule
+	// overrides, so mixed repos format everything correctly in one run,
+	// e.g. file_type "*.tofu" { dialect = "opentofu" } or a rules map
+	// disabling attribute sorting for Terragrunt *.hcl files.
+	FileTypes []FileTypeConfig `hcl:"file_type,block"`
+
+	// AttrGroups defines named attribute groups per block or resource
+	// type: groups render in the configured order, separated by blank
+	// lines and alphabetized within, with ungrouped attributes following
+	// in the usual flat group, e.g.
+	// attr_groups "aws_instance" { groups = [["ami", "instance_type"], ["subnet_id"]] }.
+	AttrGroups []AttrGroupConfig `hcl:"attr_groups,block"`
+
+	// BlockSortKeys sorts repeated nested blocks of a type by one of
+	// their attribute values, e.g. block_sort "ingress" { by = "from_port" },
+	// eliminating merge conflicts in security-group-style resources.
+	BlockSortKeys []BlockSortKey `hcl:"block_sort,block"`
+
+	// TopLevelBlockTypes overrides the built-in TopLevelBlocks list, so
+	// generic-HCL dialects and future Terraform block types get correct
+	// blank-line separation and sorting without a barry release.
+	TopLevelBlockTypes *[]string `hcl:"top_level_blocks,optional"`
+
+	// MetaArguments overrides the built-in meta-argument list from
+	// helpers.go, so organizations can add pseudo-meta arguments of
+	// their own or drop ones they disagree with.
+	MetaArguments *[]string `hcl:"meta_arguments,optional"`
+
+	// ModuleLeadingAttributes overrides the module block's built-in
+	// source/version leading group, e.g. adding "providers"; an explicit
+	// empty list disables the leading group entirely.
+	ModuleLeadingAttributes *[]string `hcl:"module_leading_attributes,optional"`
+
+	// Orders pins well-known attributes to the top of specific resource
+	// types, e.g. order "aws_instance" { first = ["ami", "instance_type"] }.
+	// It is resource-type shorthand for a leading_attributes entry.
+	Orders []LeadingAttrs `hcl:"order,block"`
+
+	// LeadingAttributes pins attributes to the front of matching
+	// blocks, generalizing the built-in module source/version case, e.g.
+	// leading_attributes "terraform_remote_state" { first = ["backend", "config"] }.
+	// The label matches the block type or, for resource-shaped blocks,
+	// the resource type.
+	LeadingAttributes []LeadingAttrs `hcl:"leading_attributes,block"`
+
+	// ExtraExtensions lists additional file suffixes (e.g. ".hcl" in a
+	// Terragrunt repo) to pick up and format as generic HCL.
+	ExtraExtensions []string `hcl:"extra_extensions,optional"`
+
+	// PathDisplay controls how paths print: "relative" to the working
+	// directory (the default), "repo" for repository-root-relative, or
+	// "absolute". PathPrefix is prepended verbatim, for containers whose
+	// mount paths differ from the host's.
+	PathDisplay string `hcl:"path_display,optional"`
+	PathPrefix  string `hcl:"path_prefix,optional"`
+
+	// Rules enables or disables individual opt-in rules by ID, e.g.
+	// rules = { sort_depends_on = true }. Unset rules keep their
+	// registered default.
+	Rules map[string]bool `hcl:"rules,optional"`
+}
+
+// FileTypeConfig is one file_type config block.
+type FileTypeConfig struct {
+	Pattern string          `hcl:"pattern,label"`
+	Dialect string          `hcl:"dialect,optional"`
+	Rules   map[string]bool `hcl:"rules,optional"`
+}
+
+// AttrGroupConfig is one attr_groups config block.
+type AttrGroupConfig struct {
+	BlockType string     `hcl:"type,label"`
+	Groups    [][]string `hcl:"groups"`
+}
+
+// BlockSortKey is one block_sort config block.
+type BlockSortKey struct {
+	BlockType string `hcl:"type,label"`
+	By        string `hcl:"by"`
+}
+
+// LeadingAttrs is one leading_attributes config block.
+type LeadingAttrs struct {
+	BlockType string   `hcl:"type,label"`
+	First     []string `hcl:"first"`
+}
+
+// cfg is the active configuration, replaced at startup once flags are
+// parsed and any config file has been read.
+var cfg = defaultConfig()
+
+func defaultConfig() *Config {
+	return &Config{
+		MetaArgsPlacement: "top",
+		MaxBlankLines:     1,
+	}
+}
+
+// loadConfig reads the config file at path, or the default .barry.hcl if
+// path is empty and one exists. A missing default file is not an error.
+func loadConfig(path string) (*Config, error) {
+	c := defaultConfig()
+	if path == "" {
+		path = configFileName
+		if _, err := os.Stat(path); err != nil {
+			return c, nil
+		}
+	}
+	parser := hclparse.NewParser()
+	f, diags := parser.ParseHCLFile(path)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	if diags := gohcl.DecodeBody(f.Body, nil, c); diags.HasErrors() {
+		return nil, diags
+	}
+	if err := c.validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return c, nil
+}
+
+func (c *Config) validate() error {
+	switch c.MetaArgsPlacement {
+	case "":
+		c.MetaArgsPlacement = "top"
+	case "top", "bottom":
+	default:
+		return fmt.Errorf("invalid meta_args_placement %q: must be \"top\" or \"bottom\"", c.MetaArgsPlacement)
+	}
+	if c.StyleVersion != "" && !knownStyleVersion(c.StyleVersion) {
+		return fmt.Errorf("unknown style_version %q: this binary knows %s", c.StyleVersion, strings.Join(styleVersions, ", "))
+	}
+	if c.NamingPattern != "" {
+		if _, err := regexp.Compile(c.NamingPattern); err != nil {
+			return fmt.Errorf("invalid naming_pattern: %w", err)
+		}
+	}
+	for _, ft := range c.FileTypes {
+		if ft.Dialect != "" {
+			if err := validDialect(ft.Dialect); err != nil {
+				return fmt.Errorf("file_type %q: %w", ft.Pattern, err)
+			}
+		}
+	}
+	switch c.PathDisplay {
+	case "", "relative", "repo", "absolute":
+	default:
+		return fmt.Errorf("invalid path_display %q: must be relative, repo or absolute", c.PathDisplay)
+	}
+	if c.MaxBlankLines < 1 {
+		c.MaxBlankLines = 1
+	}
+	known := knownRuleIDs()
+	ids := make([]string, 0, len(c.Rules))
+	for id := range c.Rules {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		if !known[id] {
+			return fmt.Errorf("rules map names unknown rule %q", id)
+		}
+	}
+	return nil
+}
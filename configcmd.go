@@ -0,0 +1,35 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+)
+
+// runConfigCmd dispatches the config subcommand family. Validation also
+// happens automatically at startup - an invalid config fails every
+// command - but "barry config validate" gives CI a dedicated check.
+func runConfigCmd(args []string) int {
+	if len(args) == 0 || args[0] != "validate" {
+		fmt.Fprintln(os.Stderr, "usage: barry config validate [-config file]")
+		return 1
+	}
+	fs := flag.NewFlagSet("config validate", flag.ExitOnError)
+	configFile := fs.String("config", "", "path to a config file (defaults to .barry.hcl if present)")
+	fs.Parse(args[1:])
+
+	path := *configFile
+	if path == "" {
+		if !fileExists(configFileName) {
+			fmt.Println("no config file; built-in defaults apply")
+			return 0
+		}
+		path = configFileName
+	}
+	if _, err := loadConfig(path); err != nil {
+		fmt.Fprintf(os.Stderr, "barry: %s\n", err)
+		return 1
+	}
+	fmt.Printf("%s is valid\n", path)
+	return 0
+}
@@ -0,0 +1,25 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"runtime/debug"
+	"time"
+)
+
+// writeCrashReport records a formatter panic to a report file in the
+// temp directory: the input path, the active configuration and the
+// stack, everything needed to reproduce the bug. The original file is
+// never touched on this path, and the returned error lets the run
+// continue with the next file.
+func writeCrashReport(path string, panicValue interface{}) error {
+	report, err := os.CreateTemp("", "barry-crash-*.txt")
+	if err != nil {
+		return fmt.Errorf("internal panic while formatting %s: %v (crash report could not be written: %s)", path, panicValue, err)
+	}
+	fmt.Fprintf(report, "barry %s crash report, %s\n\nfile: %s\npanic: %v\n\nconfig: %+v\n\n%s\n",
+		version, time.Now().Format(time.RFC3339), path, panicValue, cfg, debug.Stack())
+	report.Close()
+	return fmt.Errorf("internal panic while formatting %s; the file was left untouched and a crash report was written to %s",
+		path, report.Name())
+}
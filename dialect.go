@@ -0,0 +1,52 @@
+package main
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+)
+
+// The configuration dialects barry understands. OpenTofu syntax is a
+// superset of Terraform's at the level barry operates, so the dialect
+// mostly adjusts block classification: tofu-only blocks are first-class
+// under -dialect=opentofu and unknown trailing content otherwise.
+const (
+	dialectTerraform = "terraform"
+	dialectOpenTofu  = "opentofu"
+)
+
+// activeFileType carries the file_type entry matching the file being
+// formatted, consulted for per-file dialect and rule overrides.
+var activeFileType *FileTypeConfig
+
+// fileTypeFor returns the first file_type entry whose glob matches the
+// path or its base name.
+func fileTypeFor(p string) *FileTypeConfig {
+	for i := range cfg.FileTypes {
+		ft := &cfg.FileTypes[i]
+		if ok, err := path.Match(ft.Pattern, filepath.ToSlash(p)); err == nil && ok {
+			return ft
+		}
+		if ok, err := path.Match(ft.Pattern, filepath.Base(p)); err == nil && ok {
+			return ft
+		}
+	}
+	return nil
+}
+
+// effectiveDialect resolves the dialect for the file being formatted:
+// its file_type override when one matches, else the -dialect flag.
+func effectiveDialect() string {
+	if activeFileType != nil && activeFileType.Dialect != "" {
+		return activeFileType.Dialect
+	}
+	return opts.dialect
+}
+
+func validDialect(name string) error {
+	switch name {
+	case dialectTerraform, dialectOpenTofu:
+		return nil
+	}
+	return fmt.Errorf("unknown dialect %q: supported dialects are %s and %s", name, dialectTerraform, dialectOpenTofu)
+}
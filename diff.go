@@ -0,0 +1,268 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// A hunk is one contiguous change between two texts: old lines
+// [OldStart, OldStart+OldLines) are replaced by the NewText lines, which
+// sit at [NewStart, NewStart+NewLines) in the result. Line numbers are
+// 1-based; OldLines may be zero for a pure insertion.
+type hunk struct {
+	OldStart int
+	OldLines int
+	NewStart int
+	NewLines int
+	OldText  []string
+	NewText  []string
+}
+
+// diffOp is a single line-level edit produced by the LCS walk.
+type diffOp struct {
+	kind byte // '=', '-' or '+'
+	text string
+}
+
+// lcsLimit bounds the quadratic LCS; middles bigger than this (after
+// trimming the common prefix and suffix) collapse into one replace hunk.
+const lcsLimit = 2000
+
+func splitLines(b []byte) []string {
+	s := string(b)
+	if s == "" {
+		return nil
+	}
+	lines := strings.Split(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// diffHunks computes the changed regions between two texts line-wise.
+func diffHunks(before, after []byte) []hunk {
+	a, b := splitLines(before), splitLines(after)
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+	ma, mb := a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]
+	if len(ma) == 0 && len(mb) == 0 {
+		return nil
+	}
+	var ops []diffOp
+	if len(ma) > lcsLimit || len(mb) > lcsLimit {
+		for _, line := range ma {
+			ops = append(ops, diffOp{'-', line})
+		}
+		for _, line := range mb {
+			ops = append(ops, diffOp{'+', line})
+		}
+	} else {
+		ops = lcsOps(ma, mb)
+	}
+	return opsToHunks(ops, prefix+1, prefix+1)
+}
+
+// lcsOps walks a longest-common-subsequence table over the two line
+// slices and emits the edit script.
+func lcsOps(a, b []string) []diffOp {
+	n, m := len(a), len(b)
+	dp := make([][]int32, n+1)
+	for i := range dp {
+		dp[i] = make([]int32, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				dp[i][j] = dp[i+1][j+1] + 1
+			} else if dp[i+1][j] >= dp[i][j+1] {
+				dp[i][j] = dp[i+1][j]
+			} else {
+				dp[i][j] = dp[i][j+1]
+			}
+		}
+	}
+	var ops []diffOp
+	i, j := 0, 0
+	for i < n && j < m {
+		switch {
+		case a[i] == b[j]:
+			ops = append(ops, diffOp{'=', a[i]})
+			i++
+			j++
+		case dp[i+1][j] >= dp[i][j+1]:
+			ops = append(ops, diffOp{'-', a[i]})
+			i++
+		default:
+			ops = append(ops, diffOp{'+', b[j]})
+			j++
+		}
+	}
+	for ; i < n; i++ {
+		ops = append(ops, diffOp{'-', a[i]})
+	}
+	for ; j < m; j++ {
+		ops = append(ops, diffOp{'+', b[j]})
+	}
+	return ops
+}
+
+// renderUnifiedDiff renders hunks as a unified diff with the requested
+// amount of context around each change. Hunks whose context windows
+// touch or overlap merge into one @@ section with the equal lines
+// between them, so the output applies cleanly with git apply.
+func renderUnifiedDiff(path string, before, after []byte, context int) string {
+	hunks := diffHunks(before, after)
+	if len(hunks) == 0 {
+		return ""
+	}
+	a := splitLines(before)
+	var b strings.Builder
+	fmt.Fprintf(&b, "--- a/%s\n+++ b/%s\n", path, path)
+	for _, group := range groupHunks(hunks, context) {
+		first, last := group[0], group[len(group)-1]
+		oldBegin := first.OldStart - context
+		if oldBegin < 1 {
+			oldBegin = 1
+		}
+		oldEnd := last.OldStart + last.OldLines - 1 + context
+		if oldEnd > len(a) {
+			oldEnd = len(a)
+		}
+		oldCount := oldEnd - oldBegin + 1
+		newBegin := first.NewStart - (first.OldStart - oldBegin)
+		newCount := oldCount
+		for _, h := range group {
+			newCount += h.NewLines - h.OldLines
+		}
+		fmt.Fprintf(&b, "@@ -%d,%d +%d,%d @@\n", oldBegin, oldCount, newBegin, newCount)
+		line := oldBegin
+		for _, h := range group {
+			for ; line < h.OldStart; line++ {
+				b.WriteString(" " + a[line-1] + "\n")
+			}
+			for _, text := range h.OldText {
+				b.WriteString("-" + text + "\n")
+			}
+			for _, text := range h.NewText {
+				b.WriteString("+" + text + "\n")
+			}
+			line = h.OldStart + h.OldLines
+		}
+		for ; line <= oldEnd; line++ {
+			b.WriteString(" " + a[line-1] + "\n")
+		}
+	}
+	return b.String()
+}
+
+// groupHunks batches hunks whose expanded context regions touch or
+// overlap.
+func groupHunks(hunks []hunk, context int) [][]hunk {
+	var groups [][]hunk
+	var cur []hunk
+	for _, h := range hunks {
+		if len(cur) > 0 {
+			prev := cur[len(cur)-1]
+			prevEnd := prev.OldStart + prev.OldLines - 1 + context
+			if h.OldStart-context <= prevEnd+1 {
+				cur = append(cur, h)
+				continue
+			}
+			groups = append(groups, cur)
+		}
+		cur = []hunk{h}
+	}
+	if len(cur) > 0 {
+		groups = append(groups, cur)
+	}
+	return groups
+}
+
+// renderSideBySideDiff renders hunks in two columns for wide terminals:
+// context lines appear on both sides, deletions only on the left and
+// insertions only on the right.
+func renderSideBySideDiff(path string, before, after []byte, context int) string {
+	const col = 58
+	hunks := diffHunks(before, after)
+	if len(hunks) == 0 {
+		return ""
+	}
+	a := splitLines(before)
+	clip := func(s string) string {
+		if len(s) > col {
+			return s[:col-1] + "…"
+		}
+		return s
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s\n", path)
+	row := func(left, sep, right string) {
+		fmt.Fprintf(&b, "%-*s %s %s\n", col, clip(left), sep, clip(right))
+	}
+	for _, h := range hunks {
+		oldBegin := h.OldStart - context
+		if oldBegin < 1 {
+			oldBegin = 1
+		}
+		oldEnd := h.OldStart + h.OldLines - 1 + context
+		if oldEnd > len(a) {
+			oldEnd = len(a)
+		}
+		fmt.Fprintf(&b, "@@ line %d @@\n", h.OldStart)
+		for _, line := range a[oldBegin-1 : h.OldStart-1] {
+			row(line, "|", line)
+		}
+		for _, line := range h.OldText {
+			row(line, "<", "")
+		}
+		for _, line := range h.NewText {
+			row("", ">", line)
+		}
+		for _, line := range a[h.OldStart-1+h.OldLines : oldEnd] {
+			row(line, "|", line)
+		}
+	}
+	return b.String()
+}
+
+// opsToHunks groups runs of non-equal ops into hunks, tracking line
+// numbers on both sides.
+func opsToHunks(ops []diffOp, aLine, bLine int) []hunk {
+	var hunks []hunk
+	var cur *hunk
+	flush := func() {
+		if cur != nil {
+			hunks = append(hunks, *cur)
+			cur = nil
+		}
+	}
+	for _, op := range ops {
+		if op.kind == '=' {
+			flush()
+			aLine++
+			bLine++
+			continue
+		}
+		if cur == nil {
+			cur = &hunk{OldStart: aLine, NewStart: bLine}
+		}
+		if op.kind == '-' {
+			cur.OldLines++
+			cur.OldText = append(cur.OldText, op.text)
+			aLine++
+		} else {
+			cur.NewLines++
+			cur.NewText = append(cur.NewText, op.text)
+			bLine++
+		}
+	}
+	flush()
+	return hunks
+}
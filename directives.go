@@ -0,0 +1,31 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+)
+
+// hasDirective reports whether a token run carries the given barry
+// directive in any of its comments, e.g. "# barry:sort" in an attribute's
+// lead comment or trailing line comment.
+func hasDirective(toks hclwrite.Tokens, directive string) bool {
+	want := "barry:" + directive
+	for _, tok := range toks {
+		if tok.Type != hclsyntax.TokenComment {
+			continue
+		}
+		text := strings.TrimLeft(strings.TrimSpace(string(tok.Bytes)), "#/")
+		for _, field := range strings.Fields(text) {
+			if field == want {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func attrHasDirective(attr *hclwrite.Attribute, directive string) bool {
+	return hasDirective(attr.BuildTokens(nil), directive)
+}
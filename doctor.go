@@ -0,0 +1,109 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+)
+
+// runDoctor implements the doctor subcommand: it explains where the
+// effective configuration came from, flags settings that look wrong,
+// and verifies the optional integrations, answering "why is barry doing
+// X here" without a debugging session.
+func runDoctor(args []string) int {
+	fs := flag.NewFlagSet("doctor", flag.ExitOnError)
+	configFile := fs.String("config", "", "path to a config file (defaults to .barry.hcl if present)")
+	fs.Parse(args)
+
+	exit := 0
+	fmt.Println("configuration:")
+	path := *configFile
+	switch {
+	case path != "":
+		fmt.Printf("  config file (from -config): %s\n", path)
+	case fileExists(configFileName):
+		path = configFileName
+		fmt.Printf("  config file: %s\n", path)
+	default:
+		fmt.Println("  no config file; built-in defaults apply")
+	}
+	loaded, err := loadConfig(*configFile)
+	if err != nil {
+		fmt.Printf("  ERROR: %s\n", err)
+		return 1
+	}
+	cfg = loaded
+	if cfg.StyleVersion != "" {
+		fmt.Printf("  style_version pinned to %s (binary default %s)\n", cfg.StyleVersion, styleVersion)
+	}
+
+	fmt.Println("rules:")
+	known := knownRuleIDs()
+	ids := make([]string, 0, len(cfg.Rules))
+	for id := range cfg.Rules {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		if !known[id] {
+			fmt.Printf("  WARNING: rules map names unknown rule %q\n", id)
+			exit = 1
+			continue
+		}
+		fmt.Printf("  %s = %v\n", id, cfg.Rules[id])
+	}
+	if cfg.MaxLineLength > 0 && cfg.MaxLineLength < 40 {
+		fmt.Printf("  WARNING: max_line_length %d is below 40; most expressions will wrap\n", cfg.MaxLineLength)
+	}
+
+	fmt.Println("environment:")
+	for _, name := range []string{
+		"GITHUB_TOKEN", "GITHUB_REPOSITORY", "GITLAB_TOKEN",
+		"BITBUCKET_TOKEN", "ATLANTIS_CHANGED_FILES", "BASE_BRANCH_NAME",
+	} {
+		state := "unset"
+		if os.Getenv(name) != "" {
+			state = "set"
+		}
+		fmt.Printf("  %s: %s\n", name, state)
+	}
+
+	fmt.Println("integrations:")
+	for _, tool := range []string{"git"} {
+		if _, err := exec.LookPath(tool); err != nil {
+			fmt.Printf("  WARNING: %s not found on PATH\n", tool)
+			exit = 1
+		} else {
+			fmt.Printf("  %s: available\n", tool)
+		}
+	}
+	if cfg.PluginDir != "" {
+		bins, err := pluginBinaries()
+		if err != nil {
+			fmt.Printf("  ERROR: plugin_dir: %s\n", err)
+			exit = 1
+		} else {
+			wasm, _ := wasmPluginFiles()
+			fmt.Printf("  plugin_dir: %d exec plugin(s), %d wasm plugin(s)\n", len(bins), len(wasm))
+		}
+	}
+	if cfg.PolicyDir != "" {
+		policies, err := policyFiles()
+		if err != nil {
+			fmt.Printf("  ERROR: policy_dir: %s\n", err)
+			exit = 1
+		} else {
+			fmt.Printf("  policy_dir: %d polic(ies)\n", len(policies))
+		}
+	}
+	if cfg.EditorConfig == nil || *cfg.EditorConfig {
+		if fileExists(".editorconfig") {
+			fmt.Println("  .editorconfig: present and honoured")
+		}
+	} else {
+		fmt.Println("  .editorconfig: disabled by config")
+	}
+	return exit
+}
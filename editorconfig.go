@@ -0,0 +1,192 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// editorConfigProps are the EditorConfig properties barry honours:
+// indent_size, end_of_line, insert_final_newline and charset. Empty
+// fields mean the property was not set for the file.
+type editorConfigProps struct {
+	indentSize         int
+	endOfLine          string
+	insertFinalNewline string
+	charset            string
+}
+
+// applyEditorConfig adjusts formatted output to the .editorconfig
+// conventions that apply to the file, unless disabled in barry's own
+// config (which always wins over EditorConfig).
+func applyEditorConfig(filePath string, out []byte) []byte {
+	if cfg.EditorConfig != nil && !*cfg.EditorConfig {
+		return out
+	}
+	props := loadEditorConfig(filePath)
+	if props.indentSize > 0 && props.indentSize != 2 {
+		out = reindent(out, props.indentSize)
+	}
+	if props.insertFinalNewline == "false" {
+		out = bytes.TrimRight(out, "\n")
+	}
+	if props.charset == "utf-8-bom" && !bytes.HasPrefix(out, utf8BOM) {
+		out = append(append([]byte{}, utf8BOM...), out...)
+	}
+	if props.endOfLine == "crlf" {
+		out = bytes.ReplaceAll(out, []byte("\n"), []byte("\r\n"))
+	}
+	return out
+}
+
+var utf8BOM = []byte{0xef, 0xbb, 0xbf}
+
+// reindent rewrites the two-space indentation the formatter emits into
+// the configured width. Heredoc body lines keep their bytes: like every
+// other byte-level pass, reindentation consults the heredoc line set,
+// so an indent_size other than 2 cannot trip the heredoc invariant.
+func reindent(src []byte, size int) []byte {
+	lines := toRenderLines(src)
+	unit := strings.Repeat(" ", size)
+	var out []byte
+	for _, line := range lines {
+		text := line.text
+		if !line.protected {
+			spaces := 0
+			for spaces < len(text) && text[spaces] == ' ' {
+				spaces++
+			}
+			if spaces >= 2 {
+				text = strings.Repeat(unit, spaces/2) + text[spaces:]
+			}
+		}
+		out = append(out, text...)
+		out = append(out, '\n')
+	}
+	return out
+}
+
+// loadEditorConfig walks up from the file's directory collecting
+// .editorconfig files until one declares root = true, then resolves the
+// properties whose section globs match the file name. Nearer files win.
+func loadEditorConfig(filePath string) editorConfigProps {
+	abs, err := filepath.Abs(filePath)
+	if err != nil {
+		return editorConfigProps{}
+	}
+	name := filepath.Base(abs)
+	var configs []string
+	dir := filepath.Dir(abs)
+	for {
+		candidate := filepath.Join(dir, ".editorconfig")
+		if _, err := os.Stat(candidate); err == nil {
+			configs = append(configs, candidate)
+			if src, err := os.ReadFile(candidate); err == nil && editorConfigIsRoot(src) {
+				break
+			}
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+	}
+	var props editorConfigProps
+	// Outermost first, so closer files override.
+	for i := len(configs) - 1; i >= 0; i-- {
+		src, err := os.ReadFile(configs[i])
+		if err != nil {
+			continue
+		}
+		mergeEditorConfig(&props, src, name)
+	}
+	return props
+}
+
+func editorConfigIsRoot(src []byte) bool {
+	for _, line := range strings.Split(string(src), "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "[") {
+			return false
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if ok && strings.TrimSpace(strings.ToLower(key)) == "root" &&
+			strings.TrimSpace(strings.ToLower(value)) == "true" {
+			return true
+		}
+	}
+	return false
+}
+
+// mergeEditorConfig applies the matching sections of one .editorconfig
+// file to props, later sections overriding earlier ones.
+func mergeEditorConfig(props *editorConfigProps, src []byte, name string) {
+	matching := false
+	for _, line := range strings.Split(string(src), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			matching = editorConfigGlobMatch(line[1:len(line)-1], name)
+			continue
+		}
+		if !matching {
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		key = strings.TrimSpace(strings.ToLower(key))
+		value = strings.TrimSpace(strings.ToLower(value))
+		switch key {
+		case "indent_size":
+			if n, err := strconv.Atoi(value); err == nil && n > 0 && n <= 16 {
+				props.indentSize = n
+			}
+		case "end_of_line":
+			props.endOfLine = value
+		case "insert_final_newline":
+			props.insertFinalNewline = value
+		case "charset":
+			props.charset = value
+		}
+	}
+}
+
+// editorConfigGlobMatch matches a section pattern against the file's
+// base name, expanding {a,b} alternations. Path-qualified patterns are
+// reduced to their final segment; that covers the overwhelmingly common
+// cases without a full glob engine.
+func editorConfigGlobMatch(pattern, name string) bool {
+	if i := strings.LastIndexByte(pattern, '/'); i >= 0 {
+		pattern = pattern[i+1:]
+	}
+	for _, expanded := range expandBraces(pattern) {
+		if ok, err := path.Match(expanded, name); err == nil && ok {
+			return true
+		}
+	}
+	return false
+}
+
+func expandBraces(pattern string) []string {
+	open := strings.IndexByte(pattern, '{')
+	if open < 0 {
+		return []string{pattern}
+	}
+	close := strings.IndexByte(pattern[open:], '}')
+	if close < 0 {
+		return []string{pattern}
+	}
+	close += open
+	var out []string
+	for _, alt := range strings.Split(pattern[open+1:close], ",") {
+		out = append(out, expandBraces(pattern[:open]+alt+pattern[close+1:])...)
+	}
+	return out
+}
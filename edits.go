@@ -0,0 +1,122 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// lineOffsets returns the byte offset at which each 1-based line starts,
+// with one extra entry for the end of the text, so slicing between two
+// entries yields whole lines.
+func lineOffsets(src []byte) []int {
+	offsets := []int{0}
+	for i, b := range src {
+		if b == '\n' {
+			offsets = append(offsets, i+1)
+		}
+	}
+	if len(src) > 0 && src[len(src)-1] != '\n' {
+		offsets = append(offsets, len(src))
+	}
+	return offsets
+}
+
+// editsForChange converts a whole-file rewrite into the minimal set of
+// line-aligned byte edits, so editors can apply surgical changes and
+// keep cursor positions and undo history intact.
+func editsForChange(before, after []byte) []TextEdit {
+	offsets := lineOffsets(before)
+	offset := func(line int) int {
+		if line-1 < len(offsets) {
+			return offsets[line-1]
+		}
+		return len(before)
+	}
+	var edits []TextEdit
+	for _, h := range diffHunks(before, after) {
+		replacement := ""
+		if len(h.NewText) > 0 {
+			replacement = strings.Join(h.NewText, "\n") + "\n"
+		}
+		edits = append(edits, TextEdit{
+			Start:       offset(h.OldStart),
+			End:         offset(h.OldStart + h.OldLines),
+			Replacement: replacement,
+		})
+	}
+	return edits
+}
+
+// renderJSONDiffReport emits structured hunks per changed file - old
+// and new line ranges plus the replacement lines - so bots and IDE
+// plugins can render or apply changes without parsing unified diff
+// text.
+func renderJSONDiffReport(w io.Writer, rep *runReport) error {
+	type jsonHunk struct {
+		OldStart int      `json:"old_start"`
+		OldLines int      `json:"old_lines"`
+		NewStart int      `json:"new_start"`
+		NewLines int      `json:"new_lines"`
+		Text     []string `json:"text"`
+	}
+	type fileDiff struct {
+		File  string     `json:"file"`
+		Hunks []jsonHunk `json:"hunks"`
+	}
+	out := []fileDiff{}
+	for _, res := range rep.results {
+		if !res.Changed || res.Err != "" {
+			continue
+		}
+		fd := fileDiff{File: displayPath(res.Path)}
+		for _, h := range diffHunks(res.Before, res.After) {
+			text := h.NewText
+			if text == nil {
+				text = []string{}
+			}
+			fd.Hunks = append(fd.Hunks, jsonHunk{
+				OldStart: h.OldStart,
+				OldLines: h.OldLines,
+				NewStart: h.NewStart,
+				NewLines: h.NewLines,
+				Text:     text,
+			})
+		}
+		out = append(out, fd)
+	}
+	encoded, err := json.MarshalIndent(out, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(w, string(encoded))
+	return err
+}
+
+// renderEditsReport emits per-file byte-offset edits as JSON, one object
+// per changed file.
+func renderEditsReport(w io.Writer, rep *runReport) error {
+	type fileEdits struct {
+		File   string     `json:"file"`
+		Blocks []string   `json:"blocks,omitempty"`
+		Edits  []TextEdit `json:"edits"`
+	}
+	out := []fileEdits{}
+	for _, res := range rep.results {
+		if !res.Changed || res.Err != "" {
+			continue
+		}
+		out = append(out, fileEdits{
+			File:   displayPath(res.Path),
+			Blocks: changedBlocks(res.Path, res.Before, res.After),
+			Edits:  editsForChange(res.Before, res.After),
+		})
+	}
+	encoded, err := json.MarshalIndent(out, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(w, string(encoded))
+	return err
+}
@@ -0,0 +1,223 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+	"gopkg.in/yaml.v3"
+)
+
+// formatEmbeddedJSON pretty-prints heredoc bodies that contain valid
+// JSON, so IAM policies and container definitions stop being one-line
+// blobs. encoding/json gives a stable, sorted key order.
+func formatEmbeddedJSON(body *hclwrite.Body, name string, attr *hclwrite.Attribute) {
+	if rewriteHeredocs(body, name, attr, prettyJSON) {
+		countRule("format_json")
+	}
+}
+
+// formatEmbeddedYAML normalizes heredoc bodies containing YAML mappings
+// or sequences (cloud-init, Kubernetes manifests) via a decode/encode
+// round-trip with two-space indentation. The round-trip rewrites
+// quoting as well as indentation, which is why this is a separate rule
+// from format_json.
+func formatEmbeddedYAML(body *hclwrite.Body, name string, attr *hclwrite.Attribute) {
+	if rewriteHeredocs(body, name, attr, prettyYAML) {
+		countRule("format_yaml")
+	}
+}
+
+// rewriteHeredocs applies rewrite to the literal body of every heredoc
+// in an attribute's value. Heredocs with interpolations are never
+// touched, and the attribute is only rewritten when something actually
+// changed.
+func rewriteHeredocs(body *hclwrite.Body, name string, attr *hclwrite.Attribute, rewrite func([]byte) ([]byte, bool)) bool {
+	toks := attr.Expr().BuildTokens(nil)
+	out := make(hclwrite.Tokens, 0, len(toks))
+	changed := false
+	for i := 0; i < len(toks); i++ {
+		tok := toks[i]
+		if tok.Type != hclsyntax.TokenOHeredoc {
+			out = append(out, tok)
+			continue
+		}
+		text, end := heredocText(toks, i)
+		if end < 0 {
+			out = append(out, tok)
+			continue
+		}
+		pretty, ok := rewrite(text)
+		if !ok {
+			out = append(out, toks[i:end+1]...)
+			i = end
+			continue
+		}
+		out = append(out, tok, &hclwrite.Token{Type: hclsyntax.TokenStringLit, Bytes: pretty}, toks[end])
+		if !bytes.Equal(pretty, text) {
+			changed = true
+		}
+		i = end
+	}
+	if changed {
+		body.SetAttributeRaw(name, out)
+	}
+	return changed
+}
+
+// convertJSONHeredoc rewrites an attribute whose whole value is a
+// heredoc of static JSON into an equivalent jsonencode({...}) call, the
+// style-guide-recommended form, which then benefits from normal HCL
+// formatting on every run.
+func convertJSONHeredoc(body *hclwrite.Body, name string, attr *hclwrite.Attribute) {
+	toks := attr.Expr().BuildTokens(nil)
+	if len(toks) < 3 || toks[0].Type != hclsyntax.TokenOHeredoc ||
+		toks[len(toks)-1].Type != hclsyntax.TokenCHeredoc {
+		return
+	}
+	text, end := heredocText(toks, 0)
+	if end != len(toks)-1 {
+		return
+	}
+	trimmed := bytes.TrimSpace(text)
+	if len(trimmed) == 0 || (trimmed[0] != '{' && trimmed[0] != '[') {
+		return
+	}
+	dec := json.NewDecoder(bytes.NewReader(trimmed))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil || dec.More() {
+		return
+	}
+	val, ok := jsonToCty(v)
+	if !ok {
+		return
+	}
+	out := hclwrite.Tokens{
+		{Type: hclsyntax.TokenIdent, Bytes: []byte("jsonencode")},
+		{Type: hclsyntax.TokenOParen, Bytes: []byte("(")},
+	}
+	out = append(out, hclwrite.TokensForValue(val)...)
+	out = append(out, &hclwrite.Token{Type: hclsyntax.TokenCParen, Bytes: []byte(")")})
+	countRule("jsonencode_heredocs")
+	body.SetAttributeRaw(name, out)
+}
+
+// jsonToCty converts a decoded JSON value into a cty value whose
+// rendering via TokensForValue is a faithful HCL literal. json.Number
+// is parsed through cty to preserve the exact digits.
+func jsonToCty(v interface{}) (cty.Value, bool) {
+	switch v := v.(type) {
+	case nil:
+		return cty.NullVal(cty.DynamicPseudoType), true
+	case bool:
+		return cty.BoolVal(v), true
+	case string:
+		return cty.StringVal(v), true
+	case json.Number:
+		num, err := cty.ParseNumberVal(string(v))
+		if err != nil {
+			return cty.NilVal, false
+		}
+		return num, true
+	case []interface{}:
+		if len(v) == 0 {
+			return cty.EmptyTupleVal, true
+		}
+		elems := make([]cty.Value, len(v))
+		for i, e := range v {
+			ev, ok := jsonToCty(e)
+			if !ok {
+				return cty.NilVal, false
+			}
+			elems[i] = ev
+		}
+		return cty.TupleVal(elems), true
+	case map[string]interface{}:
+		if len(v) == 0 {
+			return cty.EmptyObjectVal, true
+		}
+		attrs := make(map[string]cty.Value, len(v))
+		for k, e := range v {
+			ev, ok := jsonToCty(e)
+			if !ok {
+				return cty.NilVal, false
+			}
+			attrs[k] = ev
+		}
+		return cty.ObjectVal(attrs), true
+	default:
+		return cty.NilVal, false
+	}
+}
+
+// heredocText concatenates the literal body of the heredoc opening at
+// start, returning the text and the index of the closing token. end is
+// -1 when the heredoc contains interpolations or is malformed.
+func heredocText(toks hclwrite.Tokens, start int) ([]byte, int) {
+	var text bytes.Buffer
+	for i := start + 1; i < len(toks); i++ {
+		switch toks[i].Type {
+		case hclsyntax.TokenStringLit:
+			text.Write(toks[i].Bytes)
+		case hclsyntax.TokenCHeredoc:
+			return text.Bytes(), i
+		default:
+			return nil, -1
+		}
+	}
+	return nil, -1
+}
+
+// prettyJSON returns the canonical two-space-indented form of src if it
+// is a JSON object or array.
+func prettyJSON(src []byte) ([]byte, bool) {
+	trimmed := bytes.TrimSpace(src)
+	if len(trimmed) == 0 || (trimmed[0] != '{' && trimmed[0] != '[') {
+		return nil, false
+	}
+	var v interface{}
+	if err := json.Unmarshal(trimmed, &v); err != nil {
+		return nil, false
+	}
+	out, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, false
+	}
+	return append(out, '\n'), true
+}
+
+// prettyYAML round-trips src through the YAML decoder when it holds a
+// mapping or sequence. Decoding into a yaml.Node keeps the document's
+// own key order, so the round-trip normalizes indentation and quoting
+// without rearranging anything. Scalars are rejected: every plain
+// string is technically YAML, and JSON-shaped documents belong to
+// format_json.
+func prettyYAML(src []byte) ([]byte, bool) {
+	trimmed := bytes.TrimSpace(src)
+	if len(trimmed) == 0 || trimmed[0] == '{' || trimmed[0] == '[' {
+		return nil, false
+	}
+	var node yaml.Node
+	if err := yaml.Unmarshal(src, &node); err != nil {
+		return nil, false
+	}
+	if node.Kind != yaml.DocumentNode || len(node.Content) != 1 {
+		return nil, false
+	}
+	switch node.Content[0].Kind {
+	case yaml.MappingNode, yaml.SequenceNode:
+	default:
+		return nil, false
+	}
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(node.Content[0]); err != nil {
+		return nil, false
+	}
+	enc.Close()
+	return buf.Bytes(), true
+}
@@ -0,0 +1,206 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+)
+
+// Arguments whose quoted values are safe to rewrite as bare literals.
+// The lists are deliberately conservative: plenty of provider-defined
+// attributes are genuinely strings that merely look numeric.
+var (
+	unquoteNumericArgs = []string{
+		"count",
+		"desired_capacity",
+		"from_port",
+		"max_size",
+		"min_size",
+		"port",
+		"priority",
+		"to_port",
+		"ttl",
+		"weight",
+	}
+	unquoteBoolArgs = []string{
+		"create_before_destroy",
+		"enabled",
+		"force_destroy",
+	}
+)
+
+var numberLitRe = regexp.MustCompile(`^[0-9]+(\.[0-9]+)?$`)
+
+// unquoteLiteralArg rewrites legacy quoted values such as count = "1"
+// and enabled = "true" into bare literals, for allowlisted argument
+// names only.
+func unquoteLiteralArg(body *hclwrite.Body, name string, attr *hclwrite.Attribute) {
+	numeric := indexOf(unquoteNumericArgs, name) >= 0
+	boolean := indexOf(unquoteBoolArgs, name) >= 0
+	if !numeric && !boolean {
+		return
+	}
+	toks := attr.Expr().BuildTokens(nil)
+	if len(toks) != 3 || toks[0].Type != hclsyntax.TokenOQuote ||
+		toks[1].Type != hclsyntax.TokenQuotedLit || toks[2].Type != hclsyntax.TokenCQuote {
+		return
+	}
+	lit := string(toks[1].Bytes)
+	switch {
+	case numeric && numberLitRe.MatchString(lit):
+		countRule("unquote_literals")
+		body.SetAttributeRaw(name, hclwrite.Tokens{
+			{Type: hclsyntax.TokenNumberLit, Bytes: []byte(lit)},
+		})
+	case boolean && (lit == "true" || lit == "false"):
+		countRule("unquote_literals")
+		body.SetAttributeRaw(name, hclwrite.Tokens{
+			{Type: hclsyntax.TokenIdent, Bytes: []byte(lit)},
+		})
+	}
+}
+
+// stripRedundantParens removes wrapping parentheses around a whole
+// attribute expression when it fits on one line. Multi-line
+// parenthesization is load-bearing in HCL - it licenses the newlines
+// inside the expression - so it is always kept.
+func stripRedundantParens(body *hclwrite.Body, name string, attr *hclwrite.Attribute) {
+	toks := attr.Expr().BuildTokens(nil)
+	out, changed := stripParens(toks)
+	if changed {
+		countRule("redundant_parens")
+		body.SetAttributeRaw(name, out)
+	}
+}
+
+// simplifyBooleanExpr rewrites the trivial boolean patterns common in
+// ported modules: x == true becomes x, x == false becomes !x, and
+// cond ? true : false becomes cond. The comparison forms only fire when
+// the left side is a plain traversal, so a lower-precedence operator can
+// never be captured by mistake.
+func simplifyBooleanExpr(body *hclwrite.Body, name string, attr *hclwrite.Attribute) {
+	toks := attr.Expr().BuildTokens(nil)
+	if out, changed := simplifyBoolTokens(toks); changed {
+		countRule("simplify_booleans")
+		body.SetAttributeRaw(name, out)
+	}
+}
+
+func simplifyBoolTokens(toks hclwrite.Tokens) (hclwrite.Tokens, bool) {
+	n := len(toks)
+	isBool := func(tok *hclwrite.Token, lit string) bool {
+		return tok.Type == hclsyntax.TokenIdent && string(tok.Bytes) == lit
+	}
+
+	if n >= 3 && toks[n-2].Type == hclsyntax.TokenEqualOp &&
+		(isBool(toks[n-1], "true") || isBool(toks[n-1], "false")) &&
+		isSimpleTraversal(toks[:n-2]) {
+		if isBool(toks[n-1], "true") {
+			return copyTokens(toks[:n-2]), true
+		}
+		out := hclwrite.Tokens{{Type: hclsyntax.TokenBang, Bytes: []byte("!")}}
+		return append(out, copyTokens(toks[:n-2])...), true
+	}
+
+	q := -1
+	depth := 0
+	for i, tok := range toks {
+		switch tok.Type {
+		case hclsyntax.TokenOParen, hclsyntax.TokenOBrack, hclsyntax.TokenOBrace:
+			depth++
+		case hclsyntax.TokenCParen, hclsyntax.TokenCBrack, hclsyntax.TokenCBrace:
+			depth--
+		case hclsyntax.TokenQuestion:
+			if depth == 0 && q < 0 {
+				q = i
+			}
+		}
+	}
+	if q > 0 && n-q == 4 && toks[q+2].Type == hclsyntax.TokenColon {
+		cond := toks[:q]
+		switch {
+		case isBool(toks[q+1], "true") && isBool(toks[q+3], "false"):
+			return copyTokens(cond), true
+		case isBool(toks[q+1], "false") && isBool(toks[q+3], "true") && isSimpleTraversal(cond):
+			out := hclwrite.Tokens{{Type: hclsyntax.TokenBang, Bytes: []byte("!")}}
+			return append(out, copyTokens(cond)...), true
+		}
+	}
+	return toks, false
+}
+
+// isSimpleTraversal reports whether a token run is a bare variable
+// reference like var.enabled, with no operators that could change
+// precedence under a rewrite.
+func isSimpleTraversal(toks hclwrite.Tokens) bool {
+	if len(toks) == 0 {
+		return false
+	}
+	for _, tok := range toks {
+		switch tok.Type {
+		case hclsyntax.TokenIdent, hclsyntax.TokenDot:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// copyTokens clones a token run, clearing the leading spacing so the run
+// can stand at the start of an expression.
+func copyTokens(toks hclwrite.Tokens) hclwrite.Tokens {
+	out := make(hclwrite.Tokens, len(toks))
+	copy(out, toks)
+	if len(out) > 0 {
+		first := *out[0]
+		first.SpacesBefore = 0
+		out[0] = &first
+	}
+	return out
+}
+
+// stripParens peels off outer parentheses that wrap the entire
+// single-line token run, repeating for ((x))-style nesting.
+func stripParens(toks hclwrite.Tokens) (hclwrite.Tokens, bool) {
+	changed := false
+	for len(toks) >= 3 && toks[0].Type == hclsyntax.TokenOParen && toks[len(toks)-1].Type == hclsyntax.TokenCParen {
+		wraps := true
+		depth := 0
+		for i, tok := range toks {
+			switch tok.Type {
+			case hclsyntax.TokenOParen:
+				depth++
+			case hclsyntax.TokenCParen:
+				depth--
+				if depth == 0 && i != len(toks)-1 {
+					wraps = false
+				}
+			case hclsyntax.TokenNewline:
+				wraps = false
+			case hclsyntax.TokenComment:
+				if strings.Contains(string(tok.Bytes), "\n") {
+					wraps = false
+				}
+			}
+			if !wraps {
+				break
+			}
+		}
+		if !wraps {
+			break
+		}
+		toks = toks[1 : len(toks)-1]
+		changed = true
+	}
+	if changed && len(toks) > 0 {
+		first := *toks[0]
+		first.SpacesBefore = 0
+		out := make(hclwrite.Tokens, len(toks))
+		copy(out, toks)
+		out[0] = &first
+		return out, true
+	}
+	return toks, changed
+}
@@ -0,0 +1,676 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+)
+
+// formatFile applies barry's ordering rules to every top-level block in
+// the parsed file, and optionally sorts the top-level blocks themselves.
+func formatFile(f *hclwrite.File) {
+	body := f.Body()
+	blocks := body.Blocks()
+	// Provider aliases are read before formatBlock rebuilds the bodies
+	// with unstructured tokens; afterwards GetAttribute cannot see them.
+	aliases := make(map[*hclwrite.Block]string)
+	for _, block := range blocks {
+		if block.Type() == "provider" {
+			aliases[block] = providerAlias(block)
+		}
+	}
+	for _, block := range blocks {
+		formatBlock(block, 0)
+	}
+	if opts.sortBlocks {
+		sortTopLevelBlocks(body, blocks, aliases)
+	}
+}
+
+// sortTopLevelBlocks reorders the file's top-level blocks, grouping them
+// by type in TopLevelBlocks order and then alphabetically by label.
+func sortTopLevelBlocks(body *hclwrite.Body, blocks []*hclwrite.Block, aliases map[*hclwrite.Block]string) {
+	names := topLevelBlockNames()
+	order := func(b *hclwrite.Block) int {
+		if i := indexOf(names, b.Type()); i >= 0 {
+			return i
+		}
+		return len(names)
+	}
+	sorted := make([]*hclwrite.Block, len(blocks))
+	copy(sorted, blocks)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		oi, oj := order(sorted[i]), order(sorted[j])
+		if oi != oj {
+			return oi < oj
+		}
+		li, lj := strings.Join(sorted[i].Labels(), "."), strings.Join(sorted[j].Labels(), ".")
+		if li != lj {
+			return li < lj
+		}
+		if sorted[i].Type() == "provider" {
+			// The default (alias-less) configuration sorts first, then
+			// aliases alphabetically; the empty alias takes care of both.
+			return aliases[sorted[i]] < aliases[sorted[j]]
+		}
+		return false
+	})
+	for _, block := range blocks {
+		body.RemoveBlock(block)
+	}
+	for i, block := range sorted {
+		if i > 0 {
+			body.AppendNewline()
+		}
+		body.AppendBlock(block)
+	}
+}
+
+// formatBlock normalizes a single block's labels and dispatches to the
+// appropriate body formatting rules for its type.
+func formatBlock(block *hclwrite.Block, depth int) {
+	// Label normalization rewrites label quoting but cannot represent
+	// comments interleaved between labels, so it is a rule of its own
+	// and warns before discarding anything.
+	if ruleEnabled("normalize_labels") {
+		if labelCommentDiscarded(block) {
+			fmt.Fprintf(os.Stderr, "barry: warning: %s %v: normalizing labels discards an inline comment (disable the normalize_labels rule to keep it)\n",
+				block.Type(), block.Labels())
+		}
+		block.SetLabels(block.Labels())
+	}
+	if ruleEnabled("expand_one_line_blocks") {
+		expandOneLineBlock(block)
+	}
+	if opts.upgradeLegacy {
+		applyUpgradeLegacy(block.Body(), block.Type(), block.Labels())
+	}
+	switch block.Type() {
+	case "dynamic":
+		formatFixedOrderBlock(block, dynamicBlockOrder, depth+1)
+	case "moved", "removed":
+		formatFixedOrderBlock(block, movedBlockOrder, depth+1)
+	case "import":
+		formatFixedOrderBlock(block, importBlockOrder, depth+1)
+	default:
+		formatBody(block.Body(), block.Type(), block.Labels(), depth+1)
+	}
+}
+
+// leadingAttrNames returns the attributes pinned to the front of a
+// block, in order: the configured leading_attributes entry for the
+// block or resource type when one matches, else the built-in module and
+// provider conventions.
+func leadingAttrNames(blockType string, labels []string) []string {
+	keys := []string{blockType}
+	if len(labels) > 0 {
+		keys = append(keys, labels[0])
+	}
+	for _, la := range cfg.LeadingAttributes {
+		if indexOf(keys, la.BlockType) >= 0 {
+			return la.First
+		}
+	}
+	for _, la := range cfg.Orders {
+		if indexOf(keys, la.BlockType) >= 0 {
+			return la.First
+		}
+	}
+	switch blockType {
+	case "module":
+		if cfg.ModuleLeadingAttributes != nil {
+			return *cfg.ModuleLeadingAttributes
+		}
+		return []string{"source", "version"}
+	case "provider":
+		return []string{"alias", "version"}
+	}
+	return nil
+}
+
+// labelCommentDiscarded reports whether rewriting the block's labels
+// would drop a comment interleaved between them. Lead comments sit
+// before the block-type identifier and are not at risk.
+func labelCommentDiscarded(block *hclwrite.Block) bool {
+	seenType := false
+	for _, tok := range block.BuildTokens(nil) {
+		switch tok.Type {
+		case hclsyntax.TokenIdent:
+			seenType = true
+		case hclsyntax.TokenOBrace:
+			return false
+		case hclsyntax.TokenComment:
+			if seenType {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// expandOneLineBlock rewrites `lifecycle { create_before_destroy = true }`
+// style one-liners into conventional multi-line form. Empty blocks keep
+// their single line. One-line block syntax only admits attributes, so
+// only attributes need re-homing.
+func expandOneLineBlock(block *hclwrite.Block) {
+	toks := block.BuildTokens(nil)
+	for _, tok := range toks[:len(toks)-1] {
+		if tok.Type == hclsyntax.TokenNewline {
+			return
+		}
+		if tok.Type == hclsyntax.TokenComment && strings.Contains(string(tok.Bytes), "\n") {
+			return
+		}
+	}
+	body := block.Body()
+	attrs := body.Attributes()
+	if len(attrs) == 0 {
+		return
+	}
+	names := make([]string, 0, len(attrs))
+	for name := range attrs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	exprs := make(map[string]hclwrite.Tokens, len(attrs))
+	for name, attr := range attrs {
+		exprs[name] = attr.Expr().BuildTokens(nil)
+	}
+	for _, name := range names {
+		body.RemoveAttribute(name)
+	}
+	body.AppendNewline()
+	for _, name := range names {
+		body.SetAttributeRaw(name, exprs[name])
+	}
+	countRule("expand_one_line_blocks")
+}
+
+// formatBody rewrites the contents of a block body: attributes are sorted
+// into groups (meta-arguments first, then everything else alphabetically,
+// with module source/version pinned ahead of both), nested blocks are
+// sorted by type, and a blank line separates each group.
+func formatBody(body *hclwrite.Body, blockType string, labels []string, depth int) {
+	// block_sort keys are read before formatBlock rebuilds the children
+	// with unstructured tokens, after which GetAttribute cannot see
+	// them; the comparator below sorts on the captured values.
+	nestedSortKeys := make(map[*hclwrite.Block]string)
+	for _, block := range body.Blocks() {
+		if by := blockSortKeyFor(block.Type()); by != "" {
+			nestedSortKeys[block] = blockAttrText(block, by)
+		}
+		formatBlock(block, depth)
+	}
+
+	if blockType == "variable" {
+		if attr, ok := body.Attributes()["type"]; ok {
+			formatTypeExpr(body, attr)
+		}
+		if attr, ok := body.Attributes()["type"]; ok {
+			formatStructuralType(body, attr)
+		}
+	}
+	applyExpressionPasses(body, blockType, depth)
+
+	if blockType == "locals" && ruleEnabled("locals_sections") {
+		// Every expression pass above has run; the section-aware
+		// ordering happens on the rendered output (sortLocalsSections),
+		// because the tree rebuild cannot reposition standalone header
+		// comments. Source order is left alone here.
+		return
+	}
+	if !ruleEnabled("attribute_ordering") {
+		return
+	}
+	formatBodyOrdering(body, blockType, labels, nestedSortKeys)
+}
+
+// applyExpressionPasses runs every per-attribute transform over a body:
+// interpolation unwrapping, directive sorting, the legacy and opt-in
+// expression rules, heredoc handling, wrapping and collapsing. It is
+// shared by block bodies and the top level of tfvars files.
+func applyExpressionPasses(body *hclwrite.Body, blockType string, depth int) {
+	for name, attr := range body.Attributes() {
+		formatValueExpr(body, name, attr)
+	}
+	for name, attr := range body.Attributes() {
+		if indexOf(cfg.SortAttributes, name) >= 0 || attrHasDirective(attr, "sort") {
+			sortLiteralCollection(body, name, attr)
+		}
+	}
+	for name, attr := range body.Attributes() {
+		applyLegacyExprFixes(body, name, attr)
+	}
+	if ruleEnabled("redundant_parens") {
+		for name, attr := range body.Attributes() {
+			stripRedundantParens(body, name, attr)
+		}
+	}
+	if ruleEnabled("simplify_booleans") {
+		for name, attr := range body.Attributes() {
+			simplifyBooleanExpr(body, name, attr)
+		}
+	}
+	if ruleEnabled("unquote_literals") {
+		for name, attr := range body.Attributes() {
+			unquoteLiteralArg(body, name, attr)
+		}
+	}
+	if ruleEnabled("format_json") {
+		for name, attr := range body.Attributes() {
+			formatEmbeddedJSON(body, name, attr)
+		}
+	}
+	if ruleEnabled("format_yaml") {
+		for name, attr := range body.Attributes() {
+			formatEmbeddedYAML(body, name, attr)
+		}
+	}
+	if ruleEnabled("jsonencode_heredocs") {
+		for name, attr := range body.Attributes() {
+			convertJSONHeredoc(body, name, attr)
+		}
+	}
+	if ruleEnabled("unquote_references") {
+		for _, name := range referenceArgs {
+			if attr, ok := body.Attributes()[name]; ok {
+				upgradeQuotedReferences(body, name, attr, legacyRefRe.MatchString)
+			}
+		}
+	}
+	if cfg.MaxLineLength > 0 {
+		for name, attr := range body.Attributes() {
+			wrapLongAttr(body, name, attr, depth*2)
+		}
+	}
+	if ruleEnabled("collapse_short_collections") {
+		for name, attr := range body.Attributes() {
+			collapseShortAttr(body, name, attr, depth*2)
+		}
+	}
+	if ruleEnabled("sort_depends_on") {
+		if attr, ok := body.Attributes()["depends_on"]; ok {
+			sortReferenceList(body, "depends_on", attr)
+		}
+		if blockType == "lifecycle" {
+			if attr, ok := body.Attributes()["ignore_changes"]; ok {
+				sortReferenceList(body, "ignore_changes", attr)
+			}
+		}
+	}
+}
+
+// formatBodyOrdering rebuilds a body with its attributes grouped and
+// sorted and its nested blocks ordered.
+func formatBodyOrdering(body *hclwrite.Body, blockType string, labels []string, nestedSortKeys map[*hclwrite.Block]string) {
+	attrs := body.Attributes()
+	pinned := leadingAttrNames(blockType, labels)
+	var leading, meta, rest, trailing []string
+	for name := range attrs {
+		switch {
+		case indexOf(pinned, name) >= 0:
+			leading = append(leading, name)
+		case cfg.LifecycleLast && name == "depends_on":
+			trailing = append(trailing, name)
+		case isMetaArgument(name):
+			meta = append(meta, name)
+		default:
+			rest = append(rest, name)
+		}
+	}
+	sort.Slice(leading, func(i, j int) bool {
+		return indexOf(pinned, leading[i]) < indexOf(pinned, leading[j])
+	})
+	metaOrder := metaArgumentNames()
+	sort.Slice(meta, func(i, j int) bool {
+		return indexOf(metaOrder, meta[i]) < indexOf(metaOrder, meta[j])
+	})
+	sort.Strings(rest)
+	if schemaAttrs := activeSchemas.lookup(blockType, labels); schemaAttrs != nil {
+		// Required attributes first, optional second, unknown last, each
+		// run alphabetized by the sort above.
+		sort.SliceStable(rest, func(i, j int) bool {
+			return schemaRank(schemaAttrs, rest[i]) < schemaRank(schemaAttrs, rest[j])
+		})
+		flagComputedOnly(schemaAttrs, rest, blockType, labels)
+	}
+
+	var custom [][]string
+	if spec := attrGroupsFor(blockType, labels); spec != nil {
+		inRest := make(map[string]bool, len(rest))
+		for _, name := range rest {
+			inRest[name] = true
+		}
+		for _, group := range spec.Groups {
+			var members []string
+			for _, name := range group {
+				if inRest[name] {
+					members = append(members, name)
+					inRest[name] = false
+				}
+			}
+			if len(members) > 0 {
+				sort.Strings(members)
+				custom = append(custom, members)
+			}
+		}
+		var remaining []string
+		for _, name := range rest {
+			if inRest[name] {
+				remaining = append(remaining, name)
+			}
+		}
+		rest = remaining
+	}
+
+	groups := [][]string{leading, meta}
+	if cfg.MetaArgsPlacement == "bottom" {
+		groups = [][]string{leading}
+	}
+	groups = append(groups, custom...)
+	groups = append(groups, rest)
+	if cfg.MetaArgsPlacement == "bottom" {
+		groups = append(groups, meta)
+	}
+	groups = append(groups, trailing)
+
+	attrTokens := make(map[string]hclwrite.Tokens, len(attrs))
+	for name, attr := range attrs {
+		attrTokens[name] = attr.BuildTokens(nil)
+	}
+	for name := range attrs {
+		body.RemoveAttribute(name)
+	}
+
+	blocks := body.Blocks()
+	sorted := make([]*hclwrite.Block, len(blocks))
+	copy(sorted, blocks)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if blockType == "check" {
+			// Assertions read as the conclusion of a check block, so any
+			// scoped data blocks come first.
+			ri, rj := checkBlockRank(sorted[i].Type()), checkBlockRank(sorted[j].Type())
+			if ri != rj {
+				return ri < rj
+			}
+		}
+		if blockType == "terraform" {
+			// cloud and backend configuration leads the terraform block;
+			// the two are mutually exclusive so they share a rank.
+			ri, rj := terraformBlockRank(sorted[i].Type()), terraformBlockRank(sorted[j].Type())
+			if ri != rj {
+				return ri < rj
+			}
+		}
+		if cfg.LifecycleLast {
+			li, lj := sorted[i].Type() == "lifecycle", sorted[j].Type() == "lifecycle"
+			if li != lj {
+				return lj
+			}
+		}
+		if sorted[i].Type() != sorted[j].Type() {
+			return sorted[i].Type() < sorted[j].Type()
+		}
+		if by := blockSortKeyFor(sorted[i].Type()); by != "" {
+			vi, vj := nestedSortKeys[sorted[i]], nestedSortKeys[sorted[j]]
+			if vi != vj {
+				return lessAttrValue(vi, vj)
+			}
+		}
+		if ruleEnabled("sort_nested_block_labels") {
+			// Same-type blocks order by label; ties keep source order via
+			// the stable sort, so repeated unlabelled blocks stay put.
+			return strings.Join(sorted[i].Labels(), ".") < strings.Join(sorted[j].Labels(), ".")
+		}
+		return false
+	})
+	for _, block := range blocks {
+		body.RemoveBlock(block)
+	}
+
+	first := true
+	for _, group := range groups {
+		if len(group) == 0 {
+			continue
+		}
+		if !first {
+			body.AppendNewline()
+		}
+		for _, name := range group {
+			body.AppendUnstructuredTokens(attrTokens[name])
+		}
+		first = false
+	}
+	for _, block := range sorted {
+		if !first {
+			body.AppendNewline()
+		}
+		body.AppendBlock(block)
+		first = false
+	}
+}
+
+// Fixed argument orders for block types whose grammar implies an order of
+// its own. The generic meta-argument and alphabetical rules would split
+// these arguments across groups (for_each and iterator in dynamic blocks)
+// or invert their natural reading order (to before from in moved blocks).
+var (
+	dynamicBlockOrder = []string{"for_each", "iterator", "labels"}
+	movedBlockOrder   = []string{"from", "to"}
+	importBlockOrder  = []string{"to", "id", "provider"}
+)
+
+// formatFixedOrderBlock orders the contents of a block with a fixed
+// argument order. Unlike formatBody, no blank lines are inserted between
+// the arguments and any nested blocks; the whole construct reads as one
+// unit.
+func formatFixedOrderBlock(block *hclwrite.Block, order []string, depth int) {
+	body := block.Body()
+	for _, b := range body.Blocks() {
+		formatBlock(b, depth)
+	}
+
+	attrs := body.Attributes()
+	names := make([]string, 0, len(attrs))
+	for _, name := range order {
+		if _, ok := attrs[name]; ok {
+			names = append(names, name)
+		}
+	}
+	var rest []string
+	for name := range attrs {
+		if indexOf(order, name) < 0 {
+			rest = append(rest, name)
+		}
+	}
+	sort.Strings(rest)
+	names = append(names, rest...)
+
+	attrTokens := make(map[string]hclwrite.Tokens, len(attrs))
+	for name, attr := range attrs {
+		attrTokens[name] = attr.BuildTokens(nil)
+	}
+	for name := range attrs {
+		body.RemoveAttribute(name)
+	}
+	blocks := body.Blocks()
+	for _, b := range blocks {
+		body.RemoveBlock(b)
+	}
+
+	for _, name := range names {
+		body.AppendUnstructuredTokens(attrTokens[name])
+	}
+	for _, b := range blocks {
+		body.AppendBlock(b)
+	}
+}
+
+// attrGroupsFor returns the attr_groups entry matching the block or
+// resource type, or nil.
+func attrGroupsFor(blockType string, labels []string) *AttrGroupConfig {
+	keys := []string{blockType}
+	if len(labels) > 0 {
+		keys = append(keys, labels[0])
+	}
+	for i := range cfg.AttrGroups {
+		if indexOf(keys, cfg.AttrGroups[i].BlockType) >= 0 {
+			return &cfg.AttrGroups[i]
+		}
+	}
+	return nil
+}
+
+// blockSortKeyFor returns the attribute configured as the sort key for
+// repeated nested blocks of the given type, or "".
+func blockSortKeyFor(blockType string) string {
+	for _, bs := range cfg.BlockSortKeys {
+		if bs.BlockType == blockType {
+			return bs.By
+		}
+	}
+	return ""
+}
+
+// blockAttrText renders a block attribute's expression as comparison
+// text; blocks missing the attribute sort first.
+func blockAttrText(block *hclwrite.Block, name string) string {
+	attr := block.Body().GetAttribute(name)
+	if attr == nil {
+		return ""
+	}
+	return tokensText(attr.Expr().BuildTokens(nil))
+}
+
+// lessAttrValue compares two sort keys numerically when both parse as
+// numbers (so port 80 sorts before 443), textually otherwise.
+func lessAttrValue(a, b string) bool {
+	na, errA := strconv.ParseFloat(strings.Trim(a, `"`), 64)
+	nb, errB := strconv.ParseFloat(strings.Trim(b, `"`), 64)
+	if errA == nil && errB == nil {
+		return na < nb
+	}
+	return a < b
+}
+
+// checkBlockRank orders the nested blocks of a check block: scoped data
+// blocks first, then assert blocks, then anything else.
+func checkBlockRank(blockType string) int {
+	switch blockType {
+	case "data":
+		return 0
+	case "assert":
+		return 1
+	default:
+		return 2
+	}
+}
+
+// terraformBlockRank orders the nested blocks of a terraform block: the
+// cloud or backend configuration first, OpenTofu's encryption block
+// next when that dialect is active, then everything else.
+func terraformBlockRank(blockType string) int {
+	switch blockType {
+	case "cloud", "backend":
+		return 0
+	case "encryption":
+		if effectiveDialect() == dialectOpenTofu {
+			return 1
+		}
+	}
+	return 2
+}
+
+// providerAlias returns the value of a provider block's alias attribute,
+// or "" for the default (alias-less) configuration.
+func providerAlias(block *hclwrite.Block) string {
+	attr := block.Body().GetAttribute("alias")
+	if attr == nil {
+		return ""
+	}
+	toks := attr.Expr().BuildTokens(nil)
+	if len(toks) == 3 && toks[0].Type == hclsyntax.TokenOQuote && toks[1].Type == hclsyntax.TokenQuotedLit {
+		return string(toks[1].Bytes)
+	}
+	return ""
+}
+
+// formatTypeExpr normalizes a variable block's type expression. Legacy
+// quoted types from Terraform 0.11 ("string", "list", "map") are rewritten
+// to their modern equivalents; bare keywords are left untouched.
+func formatTypeExpr(body *hclwrite.Body, attr *hclwrite.Attribute) {
+	toks := attr.Expr().BuildTokens(nil)
+	if len(toks) != 3 || toks[0].Type != hclsyntax.TokenOQuote ||
+		toks[1].Type != hclsyntax.TokenQuotedLit || toks[2].Type != hclsyntax.TokenCQuote {
+		return
+	}
+	var replacement string
+	switch string(toks[1].Bytes) {
+	case "string":
+		replacement = "string"
+	case "list":
+		replacement = "list(string)"
+	case "map":
+		replacement = "map(string)"
+	default:
+		return
+	}
+	body.SetAttributeRaw("type", hclwrite.Tokens{
+		{Type: hclsyntax.TokenIdent, Bytes: []byte(replacement)},
+	})
+}
+
+// formatValueExpr unwraps legacy interpolation-only expressions, turning
+// "${var.foo}" into var.foo. The unwrapping applies wherever the quoted
+// interpolation appears: as the whole attribute value, as an object
+// value or list element, or as a function argument. Templates with
+// literal parts or multiple interpolations keep their quotes.
+func formatValueExpr(body *hclwrite.Body, name string, attr *hclwrite.Attribute) {
+	toks := attr.Expr().BuildTokens(nil)
+	if out, changed := unwrapInterpolations(toks); changed {
+		body.SetAttributeRaw(name, out)
+	}
+}
+
+// unwrapInterpolations replaces every "${expr}" string whose template is
+// a single interpolation and nothing else with the bare expression,
+// recursing into the unwrapped tokens.
+func unwrapInterpolations(toks hclwrite.Tokens) (hclwrite.Tokens, bool) {
+	out := make(hclwrite.Tokens, 0, len(toks))
+	changed := false
+	for i := 0; i < len(toks); i++ {
+		tok := toks[i]
+		if tok.Type == hclsyntax.TokenOQuote && i+1 < len(toks) && toks[i+1].Type == hclsyntax.TokenTemplateInterp {
+			depth := 1
+			end := -1
+			for k := i + 2; k < len(toks) && end < 0; k++ {
+				switch toks[k].Type {
+				case hclsyntax.TokenTemplateInterp, hclsyntax.TokenTemplateControl:
+					depth++
+				case hclsyntax.TokenTemplateSeqEnd:
+					depth--
+					if depth == 0 {
+						end = k
+					}
+				}
+			}
+			if end > i+2 && end+1 < len(toks) && toks[end+1].Type == hclsyntax.TokenCQuote {
+				inner, _ := unwrapInterpolations(copyTokens(toks[i+2 : end]))
+				first := *inner[0]
+				first.SpacesBefore = tok.SpacesBefore
+				inner[0] = &first
+				out = append(out, inner...)
+				i = end + 1
+				changed = true
+				continue
+			}
+		}
+		out = append(out, tok)
+	}
+	return out, changed
+}
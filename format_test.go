@@ -0,0 +1,73 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSortTopLevelBlocksProviderAliases(t *testing.T) {
+	cfg = defaultConfig()
+	opts.sortBlocks = true
+	defer func() { opts.sortBlocks = false }()
+
+	src := []byte(`provider "aws" {
+  alias  = "zulu"
+  region = "r-zulu"
+}
+
+provider "aws" {
+  alias  = "alpha"
+  region = "r-alpha"
+}
+
+provider "aws" {
+  region = "r-default"
+}
+`)
+	out, err := formatSource("providers.tf", src)
+	if err != nil {
+		t.Fatalf("formatSource: %s", err)
+	}
+	text := string(out)
+	def := strings.Index(text, `r-default`)
+	alpha := strings.Index(text, `r-alpha`)
+	zulu := strings.Index(text, `r-zulu`)
+	if def < 0 || alpha < 0 || zulu < 0 {
+		t.Fatalf("provider bodies missing from output:\n%s", text)
+	}
+	if !(def < alpha && alpha < zulu) {
+		t.Fatalf("providers not ordered default, alpha, zulu:\n%s", text)
+	}
+}
+
+func TestBlockSortByAttributeValue(t *testing.T) {
+	cfg = defaultConfig()
+	cfg.BlockSortKeys = []BlockSortKey{{BlockType: "ingress", By: "from_port"}}
+	defer func() { cfg = defaultConfig() }()
+
+	src := []byte(`resource "aws_security_group" "sg" {
+  ingress {
+    from_port = 443
+    to_port   = 443
+  }
+
+  ingress {
+    from_port = 80
+    to_port   = 80
+  }
+}
+`)
+	out, err := formatSource("sg.tf", src)
+	if err != nil {
+		t.Fatalf("formatSource: %s", err)
+	}
+	text := string(out)
+	p80 := strings.Index(text, "from_port = 80")
+	p443 := strings.Index(text, "from_port = 443")
+	if p80 < 0 || p443 < 0 {
+		t.Fatalf("ingress blocks missing from output:\n%s", text)
+	}
+	if p80 > p443 {
+		t.Fatalf("ingress blocks not sorted by from_port:\n%s", text)
+	}
+}
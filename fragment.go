@@ -0,0 +1,92 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+)
+
+// runFragment implements -fragment: it formats a snippet of HCL that
+// need not be a valid top-level file, reading stdin (or one named file)
+// and printing the result, for templating systems and documentation
+// generators that embed partial configuration.
+func runFragment(targets []string) int {
+	var src []byte
+	var err error
+	switch {
+	case len(targets) == 0 || targets[0] == "-":
+		src, err = io.ReadAll(os.Stdin)
+	case len(targets) == 1:
+		src, err = os.ReadFile(targets[0])
+	default:
+		fmt.Fprintln(os.Stderr, "barry: -fragment takes at most one file")
+		return 1
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "barry: %s\n", err)
+		return 1
+	}
+	out, err := formatFragment(src)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "barry: %s\n", err)
+		return 1
+	}
+	os.Stdout.Write(out)
+	return 0
+}
+
+// formatFragment formats a fragment, trying it as-is first and falling
+// back to wrapping it in a synthetic block so bodies of bare attributes
+// and nested-only blocks parse, then unwrapping the result.
+func formatFragment(src []byte) ([]byte, error) {
+	if f, diags := hclwrite.ParseConfig(src, "fragment", hcl.InitialPos); !diags.HasErrors() {
+		formatFile(f)
+		return postProcess(f.Bytes(), false), nil
+	}
+	var wrapped bytes.Buffer
+	wrapped.WriteString("_fragment {\n")
+	wrapped.Write(src)
+	if len(src) > 0 && src[len(src)-1] != '\n' {
+		wrapped.WriteByte('\n')
+	}
+	wrapped.WriteString("}\n")
+	f, diags := hclwrite.ParseConfig(wrapped.Bytes(), "fragment", hcl.InitialPos)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	formatFile(f)
+	out := postProcess(f.Bytes(), false)
+	return unwrapFragment(out), nil
+}
+
+// unwrapFragment strips the synthetic _fragment wrapper and one level
+// of indentation from the formatted output.
+func unwrapFragment(out []byte) []byte {
+	lines := bytes.Split(out, []byte("\n"))
+	start, end := 0, len(lines)
+	for i, line := range lines {
+		if bytes.HasPrefix(line, []byte("_fragment {")) {
+			start = i + 1
+			break
+		}
+	}
+	for i := len(lines) - 1; i >= 0; i-- {
+		if bytes.Equal(bytes.TrimSpace(lines[i]), []byte("}")) {
+			end = i
+			break
+		}
+	}
+	if start >= end {
+		return out
+	}
+	var result bytes.Buffer
+	for _, line := range lines[start:end] {
+		result.Write(bytes.TrimPrefix(line, []byte("  ")))
+		result.WriteByte('\n')
+	}
+	return result.Bytes()
+}
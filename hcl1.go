@@ -0,0 +1,74 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+)
+
+// HCL1-era constructs that still show up in decade-old configurations.
+// When a parse fails, matching one of these turns the generic error into
+// guidance about the actual migration needed.
+var hcl1Patterns = []struct {
+	re   *regexp.Regexp
+	hint string
+}{
+	{
+		regexp.MustCompile(`(?m)^\s*[a-z_]+\s+("[^"]*"\s+)*"[^"]*\$\{`),
+		"interpolation in block labels was an HCL1 feature; labels must be static strings since Terraform 0.12",
+	},
+	{
+		regexp.MustCompile(`(?m)^\s*[a-z_]+\s+"[^"]+"\s*=\s*\{`),
+		"HCL1 accepted '=' between a block header and its body; drop the '=' for HCL2",
+	},
+	{
+		regexp.MustCompile(`(?m)^\s*"[a-zA-Z0-9_]+"\s*=`),
+		"HCL1 allowed quoted attribute names at the top level; HCL2 attribute names are bare identifiers",
+	},
+}
+
+// enrichParseError augments a parse failure before it reaches the
+// user: duplicate-definition diagnostics are rendered with both
+// definition sites and a hint, and sources matching known HCL1-era
+// shapes gain targeted migration guidance instead of a bare expression
+// error. Errors stay per-file; the run continues with the next file.
+func enrichParseError(src []byte, diags hcl.Diagnostics) error {
+	if msg := duplicateDefinitionMessage(diags); msg != "" {
+		return fmt.Errorf("%s", msg)
+	}
+	var hints []string
+	for _, pattern := range hcl1Patterns {
+		if pattern.re.Match(src) {
+			hints = append(hints, "hint: "+pattern.hint)
+		}
+	}
+	if len(hints) == 0 {
+		return diags
+	}
+	return fmt.Errorf("%s\n%s\nthis file appears to use HCL1 syntax, which barry cannot rewrite automatically",
+		diags.Error(), strings.Join(hints, "\n"))
+}
+
+// duplicateDefinitionMessage recognizes the parser's duplicate
+// attribute and repeated single-use block diagnostics and reshapes them
+// to show both definition sites.
+func duplicateDefinitionMessage(diags hcl.Diagnostics) string {
+	var parts []string
+	for _, diag := range diags {
+		if diag.Severity != hcl.DiagError {
+			continue
+		}
+		if !strings.Contains(diag.Summary, "redefined") && !strings.Contains(diag.Summary, "Duplicate") {
+			continue
+		}
+		site := ""
+		if diag.Subject != nil {
+			site = diag.Subject.String() + ": "
+		}
+		parts = append(parts, fmt.Sprintf("%sduplicate definition: %s (hint: remove or merge the duplicate; the original site is named above)",
+			site, diag.Detail))
+	}
+	return strings.Join(parts, "\n")
+}
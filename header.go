@@ -0,0 +1,33 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+)
+
+// applyHeader ensures the configured header banner (license or
+// copyright text) leads the file, inserting it with a separating blank
+// line when missing. The banner is applied to the rendered bytes after
+// every other pass, so the formatter can never mistake it for a lead
+// comment and reattach it to the first block.
+func applyHeader(src []byte) []byte {
+	if cfg.Header == "" {
+		return src
+	}
+	var banner strings.Builder
+	for _, line := range strings.Split(strings.TrimRight(cfg.Header, "\n"), "\n") {
+		if line == "" {
+			banner.WriteString("#\n")
+			continue
+		}
+		banner.WriteString("# " + line + "\n")
+	}
+	if bytes.HasPrefix(src, []byte(banner.String())) {
+		return src
+	}
+	out := []byte(banner.String())
+	if len(src) > 0 {
+		out = append(out, '\n')
+	}
+	return append(out, src...)
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/hashicorp/hcl/v2/hclwrite"
+)
+
+// MetaArgumentNames are the Terraform meta-arguments which barry groups
+// ahead of a block's other attributes, in the order they should appear
+// within that group.
+var MetaArgumentNames = []string{
+	"count",
+	"depends_on",
+	"for_each",
+	"provider",
+	"providers",
+}
+
+// TopLevelBlocks are the block types Terraform allows at the top level of
+// a configuration file, in the order barry sorts them when block sorting
+// is enabled.
+var TopLevelBlocks = []string{
+	"terraform",
+	"provider",
+	"variable",
+	"locals",
+	"data",
+	"resource",
+	"ephemeral",
+	"module",
+	"output",
+	"moved",
+	"import",
+	"removed",
+	"check",
+}
+
+// fmtSupportedExts maps the file suffixes barry handles to how they
+// are formatted: "tf" files get the full block treatment, "tfvars"
+// files syntactic cleanup only. Suffix matching (not filepath.Ext)
+// means *.auto.tfvars, terraform.tfvars and .tftest.hcl all land in
+// the right bucket.
+var fmtSupportedExts = map[string]string{
+	".tf":         "tf",
+	".tfvars":     "tfvars",
+	".tftest.hcl": "tf",
+}
+
+// fileKind classifies a path for formatting. Directory walking and the
+// formatting switch share this one table, plus any extra_extensions
+// from config (formatted as generic HCL), so the two can no longer
+// disagree.
+func fileKind(path string) string {
+	// Extension matching is case-insensitive; Windows filesystems are.
+	lower := strings.ToLower(path)
+	for ext, kind := range fmtSupportedExts {
+		if strings.HasSuffix(lower, ext) {
+			return kind
+		}
+	}
+	for _, ext := range cfg.ExtraExtensions {
+		if ext != "" && strings.HasSuffix(lower, strings.ToLower(ext)) {
+			return "tf"
+		}
+	}
+	return ""
+}
+
+func hasSupportedExt(name string) bool {
+	return fileKind(name) != ""
+}
+
+func indexOf(list []string, s string) int {
+	for i, item := range list {
+		if item == s {
+			return i
+		}
+	}
+	return -1
+}
+
+// topLevelBlockNames returns the active top-level block list: the
+// top_level_blocks config override when present, else the built-in
+// default above.
+func topLevelBlockNames() []string {
+	if cfg.TopLevelBlockTypes != nil {
+		return *cfg.TopLevelBlockTypes
+	}
+	return TopLevelBlocks
+}
+
+// metaArgumentNames returns the active meta-argument list: the
+// meta_arguments config override when present, else the built-in
+// default above.
+func metaArgumentNames() []string {
+	if cfg.MetaArguments != nil {
+		return *cfg.MetaArguments
+	}
+	return MetaArgumentNames
+}
+
+func isMetaArgument(name string) bool {
+	return indexOf(metaArgumentNames(), name) >= 0
+}
+
+// tokensText renders a token run to its source text, without any
+// inter-token spacing.
+func tokensText(toks hclwrite.Tokens) string {
+	var b strings.Builder
+	for _, tok := range toks {
+		b.Write(tok.Bytes)
+	}
+	return b.String()
+}
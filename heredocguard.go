@@ -0,0 +1,96 @@
+package main
+
+import (
+	"sort"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+// A renderLine is one line of rendered output flowing through the
+// byte-level cleanup passes, carrying whether it lies inside a heredoc
+// body. Protected lines pass through every cleanup untouched, which is
+// what makes "never modify heredoc body bytes" an invariant of the
+// pipeline rather than a hope.
+type renderLine struct {
+	text      string
+	protected bool
+}
+
+// heredocLineSet lexes rendered source and returns the 1-based line
+// numbers lying inside heredoc bodies (exclusive of the opening and
+// closing marker lines).
+func heredocLineSet(src []byte) map[int]bool {
+	toks, _ := hclsyntax.LexConfig(src, "heredoc-guard", hcl.InitialPos)
+	protected := make(map[int]bool)
+	open := -1
+	for _, tok := range toks {
+		switch tok.Type {
+		case hclsyntax.TokenOHeredoc:
+			// The opener token ends at the start of the body's first line.
+			open = tok.Range.End.Line
+		case hclsyntax.TokenCHeredoc:
+			if open >= 0 {
+				for line := open; line < tok.Range.Start.Line; line++ {
+					protected[line] = true
+				}
+			}
+			open = -1
+		}
+	}
+	return protected
+}
+
+// toRenderLines splits rendered bytes into lines tagged with heredoc
+// protection.
+func toRenderLines(src []byte) []renderLine {
+	protected := heredocLineSet(src)
+	raw := splitLines(src)
+	lines := make([]renderLine, len(raw))
+	for i, text := range raw {
+		lines[i] = renderLine{text: text, protected: protected[i+1]}
+	}
+	return lines
+}
+
+// heredocBodies collects every heredoc body text in the source, sorted
+// so order-insensitive comparison works across attribute reordering.
+func heredocBodies(src []byte) []string {
+	toks, _ := hclsyntax.LexConfig(src, "heredoc-guard", hcl.InitialPos)
+	var bodies []string
+	open := -1
+	for _, tok := range toks {
+		switch tok.Type {
+		case hclsyntax.TokenOHeredoc:
+			open = tok.Range.End.Byte
+		case hclsyntax.TokenCHeredoc:
+			if open >= 0 && tok.Range.Start.Byte >= open && tok.Range.Start.Byte <= len(src) {
+				bodies = append(bodies, string(src[open:tok.Range.Start.Byte]))
+			}
+			open = -1
+		}
+	}
+	sort.Strings(bodies)
+	return bodies
+}
+
+// heredocRulesActive reports whether any rule that legitimately rewrites
+// heredoc contents is enabled.
+func heredocRulesActive() bool {
+	return ruleEnabled("format_json") || ruleEnabled("format_yaml") || ruleEnabled("jsonencode_heredocs")
+}
+
+// heredocBodiesEqual compares the heredoc body multisets of two
+// sources.
+func heredocBodiesEqual(before, after []byte) bool {
+	a, b := heredocBodies(before), heredocBodies(after)
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
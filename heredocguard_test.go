@@ -0,0 +1,76 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+)
+
+var heredocFixture = []byte(`resource "null_resource" "x" {
+  script = <<EOT
+// not a comment
+#banner
+##double banner
+
+
+   spaced   line with trailing   
+resource "looks" "like_a_block" {
+EOT
+}
+`)
+
+func TestHeredocLineSet(t *testing.T) {
+	protected := heredocLineSet(heredocFixture)
+	for line := 3; line <= 9; line++ {
+		if !protected[line] {
+			t.Errorf("body line %d not protected", line)
+		}
+	}
+	for _, line := range []int{1, 2, 10, 11} {
+		if protected[line] {
+			t.Errorf("non-body line %d wrongly protected", line)
+		}
+	}
+}
+
+// TestPostProcessPreservesHeredocBytes exercises the byte-level passes
+// that historically corrupted heredocs: comment conversion, banner and
+// spacing normalization, blank-line collapsing and top-level block
+// separation must all leave body bytes alone.
+func TestPostProcessPreservesHeredocBytes(t *testing.T) {
+	cfg = defaultConfig()
+	cfg.Rules = map[string]bool{
+		"comment_spacing":  true,
+		"collapse_banners": true,
+		"reflow_comments":  true,
+	}
+	defer func() { cfg = defaultConfig() }()
+
+	out := postProcess(heredocFixture, true)
+	for _, body := range [][]byte{
+		[]byte("// not a comment\n"),
+		[]byte("#banner\n"),
+		[]byte("##double banner\n"),
+		[]byte("\n\n\n"),
+		[]byte("   spaced   line with trailing   \n"),
+		[]byte("resource \"looks\" \"like_a_block\" {\n"),
+	} {
+		if !bytes.Contains(out, body) {
+			t.Errorf("heredoc body bytes %q modified:\n%s", body, out)
+		}
+	}
+}
+
+// TestFormatSourceHeredocInvariant runs the whole pipeline and checks
+// the invariant the guard enforces: without a heredoc rule enabled, the
+// heredoc body multiset of input and output must match.
+func TestFormatSourceHeredocInvariant(t *testing.T) {
+	cfg = defaultConfig()
+	out, err := formatSource("main.tf", heredocFixture)
+	if err != nil {
+		t.Fatalf("formatSource: %s", err)
+	}
+	if !heredocBodiesEqual(heredocFixture, out) {
+		t.Fatalf("heredoc bodies changed:\nbefore: %q\nafter: %q",
+			heredocBodies(heredocFixture), heredocBodies(out))
+	}
+}
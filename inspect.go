@@ -0,0 +1,169 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+// runInspect dispatches the inspect subcommand family, the debugging
+// window into what barry actually parsed - invaluable in bug reports
+// and when writing custom rules.
+func runInspect(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, "usage: barry inspect ast|tokens <file>")
+		return 1
+	}
+	switch args[0] {
+	case "ast":
+		return runInspectAST(args[1:])
+	case "tokens":
+		return runInspectTokens(args[1:])
+	default:
+		fmt.Fprintf(os.Stderr, "barry: unknown inspect target %q\n", args[0])
+		return 1
+	}
+}
+
+// An astNode is one entry in the parsed hierarchy: a block with its
+// children, or an attribute with its expression source text.
+type astNode struct {
+	Kind     string     `json:"kind"`
+	Type     string     `json:"type,omitempty"`
+	Labels   []string   `json:"labels,omitempty"`
+	Name     string     `json:"name,omitempty"`
+	Expr     string     `json:"expr,omitempty"`
+	Line     int        `json:"line"`
+	Children []*astNode `json:"children,omitempty"`
+}
+
+func runInspectAST(args []string) int {
+	fs := flag.NewFlagSet("inspect ast", flag.ExitOnError)
+	jsonOut := fs.Bool("json", false, "emit the hierarchy as JSON")
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "usage: barry inspect ast <file>")
+		return 1
+	}
+	path := fs.Arg(0)
+	src, err := os.ReadFile(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "barry: %s\n", err)
+		return 1
+	}
+	pf, err := parseSyntaxFile(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "barry: %s\n", err)
+		return 1
+	}
+	nodes := astBodyNodes(src, pf.body)
+	if *jsonOut {
+		out, _ := json.MarshalIndent(nodes, "", "  ")
+		fmt.Println(string(out))
+		return 0
+	}
+	printASTNodes(nodes, 0)
+	return 0
+}
+
+// runInspectTokens dumps the hclsyntax token stream of a file, the view
+// needed when a construct trips the lexer-level passes. Lex errors go
+// to stderr but the stream still prints; a broken stream is exactly
+// what one wants to see.
+func runInspectTokens(args []string) int {
+	fs := flag.NewFlagSet("inspect tokens", flag.ExitOnError)
+	jsonOut := fs.Bool("json", false, "emit the token stream as JSON")
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "usage: barry inspect tokens <file>")
+		return 1
+	}
+	path := fs.Arg(0)
+	src, err := os.ReadFile(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "barry: %s\n", err)
+		return 1
+	}
+	toks, diags := hclsyntax.LexConfig(src, path, hcl.InitialPos)
+	if diags.HasErrors() {
+		fmt.Fprintf(os.Stderr, "barry: %s\n", diags)
+	}
+	if *jsonOut {
+		type tokenDump struct {
+			Type   string `json:"type"`
+			Bytes  string `json:"bytes"`
+			Line   int    `json:"line"`
+			Column int    `json:"column"`
+			Start  int    `json:"start"`
+			End    int    `json:"end"`
+		}
+		dump := make([]tokenDump, 0, len(toks))
+		for _, tok := range toks {
+			dump = append(dump, tokenDump{
+				Type:   tok.Type.String(),
+				Bytes:  string(tok.Bytes),
+				Line:   tok.Range.Start.Line,
+				Column: tok.Range.Start.Column,
+				Start:  tok.Range.Start.Byte,
+				End:    tok.Range.End.Byte,
+			})
+		}
+		out, _ := json.MarshalIndent(dump, "", "  ")
+		fmt.Println(string(out))
+		return 0
+	}
+	for _, tok := range toks {
+		fmt.Printf("%4d:%-4d %-24s %q\n",
+			tok.Range.Start.Line, tok.Range.Start.Column, tok.Type.String(), tok.Bytes)
+	}
+	return 0
+}
+
+// astBodyNodes renders a body's attributes and blocks in source order.
+func astBodyNodes(src []byte, body *hclsyntax.Body) []*astNode {
+	var nodes []*astNode
+	for name, attr := range body.Attributes {
+		rng := attr.Expr.Range()
+		nodes = append(nodes, &astNode{
+			Kind: "attribute",
+			Name: name,
+			Expr: string(src[rng.Start.Byte:rng.End.Byte]),
+			Line: attr.NameRange.Start.Line,
+		})
+	}
+	for _, block := range body.Blocks {
+		nodes = append(nodes, &astNode{
+			Kind:     "block",
+			Type:     block.Type,
+			Labels:   block.Labels,
+			Line:     block.TypeRange.Start.Line,
+			Children: astBodyNodes(src, block.Body),
+		})
+	}
+	sort.SliceStable(nodes, func(i, j int) bool {
+		return nodes[i].Line < nodes[j].Line
+	})
+	return nodes
+}
+
+func printASTNodes(nodes []*astNode, depth int) {
+	indent := strings.Repeat("  ", depth)
+	for _, node := range nodes {
+		if node.Kind == "attribute" {
+			fmt.Printf("%s%d: %s = %s\n", indent, node.Line, node.Name, node.Expr)
+			continue
+		}
+		label := node.Type
+		if len(node.Labels) > 0 {
+			label += " " + strings.Join(node.Labels, " ")
+		}
+		fmt.Printf("%s%d: block %s\n", indent, node.Line, label)
+		printASTNodes(node.Children, depth+1)
+	}
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+)
+
+// formatWithKeeps handles files containing # barry:keep directives. A
+// block with the directive in the comment run immediately above it
+// keeps its exact bytes - no reordering, no expression rewriting, not
+// even whitespace normalization, which distinguishes keep from an
+// ignore that still allows cleanup - while everything around it
+// formats normally. ok is false when no block actually carries the
+// directive.
+func formatWithKeeps(path string, src []byte) ([]byte, bool) {
+	f, diags := hclsyntax.ParseConfig(src, path, hcl.InitialPos)
+	if diags.HasErrors() {
+		return nil, false
+	}
+	body, okBody := f.Body.(*hclsyntax.Body)
+	if !okBody {
+		return nil, false
+	}
+	lines := strings.SplitAfter(string(src), "\n")
+	offsets := make([]int, len(lines)+1)
+	for i, line := range lines {
+		offsets[i+1] = offsets[i] + len(line)
+	}
+	lineStart := func(line int) int { // 1-based, clamped
+		if line-1 >= len(offsets) {
+			return len(src)
+		}
+		if line < 1 {
+			return 0
+		}
+		return offsets[line-1]
+	}
+
+	type span struct{ start, end int }
+	var spans []span
+	for _, block := range body.Blocks {
+		first := block.DefRange().Start.Line
+		// Walk up through the contiguous comment run above the block,
+		// looking for the directive.
+		top := first
+		keep := false
+		for top > 1 {
+			line := strings.TrimSpace(lines[top-2])
+			if !strings.HasPrefix(line, "#") && !strings.HasPrefix(line, "//") {
+				break
+			}
+			top--
+			if hasDirective(hclwrite.Tokens{{Type: hclsyntax.TokenComment, Bytes: []byte(line)}}, "keep") {
+				keep = true
+			}
+		}
+		if keep {
+			spans = append(spans, span{
+				start: lineStart(top),
+				end:   lineStart(block.Body.EndRange.End.Line + 1),
+			})
+		}
+	}
+	if len(spans) == 0 {
+		return nil, false
+	}
+
+	var pieces [][]byte
+	prev := 0
+	for _, s := range spans {
+		if between := bytes.TrimSpace(src[prev:s.start]); len(between) > 0 {
+			pieces = append(pieces, formatKeepSegment(path, src[prev:s.start]))
+		}
+		pieces = append(pieces, bytes.TrimRight(src[s.start:s.end], "\n"))
+		prev = s.end
+	}
+	if rest := bytes.TrimSpace(src[prev:]); len(rest) > 0 {
+		pieces = append(pieces, formatKeepSegment(path, src[prev:]))
+	}
+
+	var out bytes.Buffer
+	for i, piece := range pieces {
+		if i > 0 {
+			out.WriteString("\n")
+		}
+		out.Write(piece)
+		out.WriteString("\n")
+	}
+	return out.Bytes(), true
+}
+
+// formatKeepSegment formats the source between kept blocks through the
+// normal pipeline, falling back to the raw text if the segment does not
+// parse on its own. Blank lines on both edges are trimmed so assembly
+// alone controls the spacing between pieces; a formatted segment
+// otherwise keeps the leading newline of its source and a kept block
+// ends up followed by two blank lines.
+func formatKeepSegment(path string, segment []byte) []byte {
+	f, diags := hclwrite.ParseConfig(segment, path, hcl.InitialPos)
+	if diags.HasErrors() {
+		return bytes.Trim(segment, "\n")
+	}
+	formatFile(f)
+	return bytes.Trim(postProcess(f.Bytes(), true), "\n")
+}
@@ -0,0 +1,301 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+)
+
+// upgradeWarnings collects the constructs -upgrade-legacy saw but could
+// not rewrite safely; they are reported at the end of the run so users
+// know what manual migration remains.
+var (
+	upgradeWarningsMu sync.Mutex
+	upgradeWarnings   []string
+)
+
+func upgradeWarnf(format string, args ...interface{}) {
+	upgradeWarningsMu.Lock()
+	defer upgradeWarningsMu.Unlock()
+	upgradeWarnings = append(upgradeWarnings, fmt.Sprintf(format, args...))
+}
+
+// applyUpgradeLegacy is the aggressive half of -upgrade-legacy, run on
+// every body. Outside the reference-bearing arguments (depends_on,
+// provider, providers) a quoted string only counts as a reference when
+// its first component is a known reference root; ordinary dotted data
+// like "www.example.com" keeps its quotes. Constructs with no safe
+// rewrite are recorded for the report.
+func applyUpgradeLegacy(body *hclwrite.Body, blockType string, labels []string) {
+	for name, attr := range body.Attributes() {
+		matches := isReferenceText
+		if indexOf(referenceArgs, name) >= 0 {
+			matches = legacyRefRe.MatchString
+		}
+		upgradeQuotedReferences(body, name, attr, matches)
+	}
+	if attr, ok := body.Attributes()["depends_on"]; ok {
+		for _, tok := range attr.Expr().BuildTokens(nil) {
+			if tok.Type == hclsyntax.TokenOQuote {
+				upgradeWarnf("%s %v: depends_on still contains quoted entries that do not look like references", blockType, labels)
+				break
+			}
+		}
+	}
+	if blockType == "provider" {
+		if _, ok := body.Attributes()["version"]; ok {
+			upgradeWarnf("provider %v: the version argument belongs in required_providers and was not moved automatically", labels)
+		}
+	}
+}
+
+// legacyRefRe matches the textual form of a reference as it appeared in
+// quoted Terraform 0.11-era expressions, e.g. "aws_instance.web" or
+// "module.vpc.vpc_id".
+var legacyRefRe = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_-]*(\.[a-zA-Z0-9_-]+)+$`)
+
+// referenceArgs are the arguments whose string values are references by
+// definition, where any dotted word may be unquoted.
+var referenceArgs = []string{"depends_on", "provider", "providers"}
+
+// referenceRoots are the first traversal components that mark a quoted
+// string as a real reference in contexts where arbitrary string data is
+// equally plausible.
+var referenceRoots = []string{"var", "local", "module", "data", "each", "count", "path", "terraform"}
+
+// isReferenceText reports whether a quoted string is safe to rewrite as
+// a bare reference outside the reference-bearing arguments.
+func isReferenceText(text string) bool {
+	if !legacyRefRe.MatchString(text) {
+		return false
+	}
+	root := text[:strings.IndexByte(text, '.')]
+	return indexOf(referenceRoots, root) >= 0
+}
+
+// upgradeQuotedReferences rewrites legacy quoted references appearing
+// anywhere in an attribute's value into bare references, covering both
+// depends_on = ["aws_instance.x"] and providers = { aws = "aws.east" },
+// as well as "${aws_instance.x}"-style quoted interpolations.
+func upgradeQuotedReferences(body *hclwrite.Body, name string, attr *hclwrite.Attribute, matches func(string) bool) {
+	toks := attr.Expr().BuildTokens(nil)
+	out := make(hclwrite.Tokens, 0, len(toks))
+	changed := false
+	for i := 0; i < len(toks); {
+		rep, consumed := unquoteReference(toks[i:], matches)
+		if consumed > 0 {
+			out = append(out, rep...)
+			i += consumed
+			changed = true
+			continue
+		}
+		out = append(out, toks[i])
+		i++
+	}
+	if changed {
+		countRule("unquote_references")
+		body.SetAttributeRaw(name, out)
+	}
+}
+
+// identRe matches a bare HCL identifier, used when deciding whether a
+// legacy map() key can be written without quotes.
+var identRe = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_-]*$`)
+
+// applyLegacyExprFixes applies the fix-legacy expression rewrites to a
+// single attribute value, honouring the individual rule toggles.
+func applyLegacyExprFixes(body *hclwrite.Body, name string, attr *hclwrite.Attribute) {
+	toks := attr.Expr().BuildTokens(nil)
+	changed := false
+	if ruleEnabled("legacy_splat") {
+		if out, c := fixLegacySplat(toks); c {
+			toks, changed = out, true
+			countRule("legacy_splat")
+		}
+	}
+	if ruleEnabled("legacy_element") {
+		if out, c := fixLegacyElement(toks); c {
+			toks, changed = out, true
+			countRule("legacy_element")
+		}
+	}
+	if ruleEnabled("legacy_collections") {
+		if out, c := fixLegacyCollections(toks); c {
+			toks, changed = out, true
+			countRule("legacy_collections")
+		}
+	}
+	if changed {
+		body.SetAttributeRaw(name, toks)
+	}
+}
+
+// fixLegacySplat rewrites attribute-only splats (a.*.id) to the modern
+// full splat form (a[*].id).
+func fixLegacySplat(toks hclwrite.Tokens) (hclwrite.Tokens, bool) {
+	out := make(hclwrite.Tokens, 0, len(toks))
+	changed := false
+	for i := 0; i < len(toks); i++ {
+		if toks[i].Type == hclsyntax.TokenDot && i+1 < len(toks) &&
+			toks[i+1].Type == hclsyntax.TokenStar && toks[i+1].SpacesBefore == 0 {
+			out = append(out,
+				&hclwrite.Token{Type: hclsyntax.TokenOBrack, Bytes: []byte("["), SpacesBefore: toks[i].SpacesBefore},
+				&hclwrite.Token{Type: hclsyntax.TokenStar, Bytes: []byte("*")},
+				&hclwrite.Token{Type: hclsyntax.TokenCBrack, Bytes: []byte("]")})
+			i++
+			changed = true
+			continue
+		}
+		out = append(out, toks[i])
+	}
+	return out, changed
+}
+
+// fixLegacyElement rewrites element(list, i) to list[i] when the whole
+// expression is the call, the list is a plain traversal and the index is
+// trivial. element's wrap-around behaviour means anything fancier is not
+// safe to rewrite mechanically.
+func fixLegacyElement(toks hclwrite.Tokens) (hclwrite.Tokens, bool) {
+	if len(toks) < 5 || toks[0].Type != hclsyntax.TokenIdent || string(toks[0].Bytes) != "element" ||
+		toks[1].Type != hclsyntax.TokenOParen || toks[len(toks)-1].Type != hclsyntax.TokenCParen {
+		return toks, false
+	}
+	args, ok := splitCallArgs(toks[2 : len(toks)-1])
+	if !ok || len(args) != 2 {
+		return toks, false
+	}
+	for _, tok := range args[0] {
+		switch tok.Type {
+		case hclsyntax.TokenIdent, hclsyntax.TokenDot:
+		default:
+			return toks, false
+		}
+	}
+	for _, tok := range args[1] {
+		switch tok.Type {
+		case hclsyntax.TokenIdent, hclsyntax.TokenDot, hclsyntax.TokenNumberLit:
+		default:
+			return toks, false
+		}
+	}
+	out := make(hclwrite.Tokens, 0, len(args[0])+len(args[1])+2)
+	out = append(out, args[0]...)
+	first := *out[0]
+	first.SpacesBefore = 0
+	out[0] = &first
+	out = append(out, &hclwrite.Token{Type: hclsyntax.TokenOBrack, Bytes: []byte("[")})
+	out = append(out, args[1]...)
+	out = append(out, &hclwrite.Token{Type: hclsyntax.TokenCBrack, Bytes: []byte("]")})
+	return out, true
+}
+
+// fixLegacyCollections rewrites whole-expression list(...) and map(...)
+// calls to their literal [...] and {...} forms.
+func fixLegacyCollections(toks hclwrite.Tokens) (hclwrite.Tokens, bool) {
+	if len(toks) < 3 || toks[0].Type != hclsyntax.TokenIdent ||
+		toks[1].Type != hclsyntax.TokenOParen || toks[len(toks)-1].Type != hclsyntax.TokenCParen {
+		return toks, false
+	}
+	inner := toks[2 : len(toks)-1]
+	switch string(toks[0].Bytes) {
+	case "list":
+		out := hclwrite.Tokens{{Type: hclsyntax.TokenOBrack, Bytes: []byte("[")}}
+		out = append(out, inner...)
+		out = append(out, &hclwrite.Token{Type: hclsyntax.TokenCBrack, Bytes: []byte("]")})
+		return out, true
+	case "map":
+		args, ok := splitCallArgs(inner)
+		if !ok || len(args) == 0 || len(args)%2 != 0 {
+			return toks, false
+		}
+		out := hclwrite.Tokens{{Type: hclsyntax.TokenOBrace, Bytes: []byte("{")}}
+		for i := 0; i < len(args); i += 2 {
+			key, value := args[i], args[i+1]
+			if i > 0 {
+				out = append(out, &hclwrite.Token{Type: hclsyntax.TokenComma, Bytes: []byte(",")})
+			}
+			if len(key) == 3 && key[0].Type == hclsyntax.TokenOQuote &&
+				key[1].Type == hclsyntax.TokenQuotedLit && identRe.Match(key[1].Bytes) {
+				out = append(out, &hclwrite.Token{Type: hclsyntax.TokenIdent, Bytes: key[1].Bytes, SpacesBefore: 1})
+			} else {
+				out = append(out, key...)
+			}
+			out = append(out, &hclwrite.Token{Type: hclsyntax.TokenEqual, Bytes: []byte("="), SpacesBefore: 1})
+			out = append(out, value...)
+		}
+		out = append(out, &hclwrite.Token{Type: hclsyntax.TokenCBrace, Bytes: []byte("}"), SpacesBefore: 1})
+		return out, true
+	}
+	return toks, false
+}
+
+// splitCallArgs splits a function call's argument tokens on top-level
+// commas. Multi-line argument lists are not handled; ok is false.
+func splitCallArgs(toks hclwrite.Tokens) ([]hclwrite.Tokens, bool) {
+	var args []hclwrite.Tokens
+	var cur hclwrite.Tokens
+	depth := 0
+	for _, tok := range toks {
+		switch tok.Type {
+		case hclsyntax.TokenOParen, hclsyntax.TokenOBrack, hclsyntax.TokenOBrace:
+			depth++
+		case hclsyntax.TokenCParen, hclsyntax.TokenCBrack, hclsyntax.TokenCBrace:
+			depth--
+		case hclsyntax.TokenNewline:
+			return nil, false
+		}
+		if depth == 0 && tok.Type == hclsyntax.TokenComma {
+			args = append(args, cur)
+			cur = nil
+			continue
+		}
+		cur = append(cur, tok)
+	}
+	if len(cur) > 0 {
+		args = append(args, cur)
+	}
+	return args, true
+}
+
+// unquoteReference inspects the start of a token stream for a quoted
+// reference ("aws_instance.x") or an interpolation-only quoted reference
+// ("${aws_instance.x}") and returns the bare replacement tokens along
+// with how many input tokens they replace. consumed is zero when the
+// stream does not start with an upgradable reference.
+func unquoteReference(toks hclwrite.Tokens, matches func(string) bool) (rep hclwrite.Tokens, consumed int) {
+	if len(toks) >= 3 && toks[0].Type == hclsyntax.TokenOQuote &&
+		toks[1].Type == hclsyntax.TokenQuotedLit && toks[2].Type == hclsyntax.TokenCQuote {
+		if !matches(string(toks[1].Bytes)) {
+			return nil, 0
+		}
+		return hclwrite.Tokens{{
+			Type:         hclsyntax.TokenIdent,
+			Bytes:        toks[1].Bytes,
+			SpacesBefore: toks[0].SpacesBefore,
+		}}, 3
+	}
+	if len(toks) >= 5 && toks[0].Type == hclsyntax.TokenOQuote && toks[1].Type == hclsyntax.TokenTemplateInterp {
+		for i := 2; i < len(toks)-1; i++ {
+			switch toks[i].Type {
+			case hclsyntax.TokenIdent, hclsyntax.TokenDot:
+			case hclsyntax.TokenTemplateSeqEnd:
+				if i == 2 || toks[i+1].Type != hclsyntax.TokenCQuote {
+					return nil, 0
+				}
+				rep = make(hclwrite.Tokens, i-2)
+				copy(rep, toks[2:i])
+				first := *rep[0]
+				first.SpacesBefore = toks[0].SpacesBefore
+				rep[0] = &first
+				return rep, i + 2
+			default:
+				return nil, 0
+			}
+		}
+	}
+	return nil, 0
+}
@@ -0,0 +1,256 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+// A Diagnostic is a single lint finding, positioned in the source.
+type Diagnostic struct {
+	Rule    string `json:"rule"`
+	File    string `json:"file"`
+	Line    int    `json:"line"`
+	Column  int    `json:"column"`
+	Message string `json:"message"`
+}
+
+// A LintRule inspects a parsed file and reports style problems that have
+// no safe automatic fix. Lint rules share the rules map in configuration
+// with the formatting rules, but default to enabled.
+type LintRule struct {
+	ID          string
+	Description string
+	Check       func(path string, body *hclsyntax.Body) []Diagnostic
+	// Fix, when non-nil, rewrites the file to resolve the rule's
+	// findings. It only runs when lint is invoked with -fix.
+	Fix func(path string) error
+}
+
+// LintRules is the registry of lint rules, in the order they were
+// introduced.
+var LintRules = []*LintRule{
+	missingDescriptionRule,
+	namingRule,
+	schemaTypoRule,
+}
+
+// A parsedFile pairs a path with its parsed syntax body, for rules that
+// look across a whole module directory rather than one file at a time.
+type parsedFile struct {
+	path string
+	body *hclsyntax.Body
+}
+
+// A ModuleLintRule inspects every parsed file of a lint target together.
+type ModuleLintRule struct {
+	ID          string
+	Description string
+	Check       func(files []*parsedFile) []Diagnostic
+	// Fix, when non-nil, rewrites files to resolve the rule's findings
+	// and returns the paths it changed so they can be re-parsed. It only
+	// runs when lint is invoked with -fix.
+	Fix func(files []*parsedFile) ([]string, error)
+}
+
+// ModuleLintRules is the registry of module-wide lint rules.
+var ModuleLintRules = []*ModuleLintRule{
+	duplicateAddressRule,
+	unusedDefinitionsRule,
+	tfvarsConsistencyRule,
+}
+
+func lintRuleEnabled(id string) bool {
+	if v, ok := cfg.Rules[id]; ok {
+		return v
+	}
+	return true
+}
+
+// runLint implements the lint subcommand. Files are checked one by one;
+// diagnostics are printed in file/position order, as text or JSON.
+func runLint(args []string) int {
+	fs := flag.NewFlagSet("lint", flag.ExitOnError)
+	configFile := fs.String("config", "", "path to a config file (defaults to .barry.hcl if present)")
+	jsonOut := fs.Bool("json", false, "emit diagnostics as JSON")
+	fix := fs.Bool("fix", false, "apply automatic fixes where rules provide them")
+	schemaFile := fs.String("schema-file", "", "terraform providers schema -json output enabling schema-based rules")
+	fs.Parse(args)
+
+	var err error
+	cfg, err = loadConfig(*configFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "barry: %s\n", err)
+		return 1
+	}
+	if *schemaFile != "" {
+		activeSchemas, err = loadProviderSchemas(*schemaFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "barry: %s\n", err)
+			return 1
+		}
+	}
+	targets := fs.Args()
+	if len(targets) == 0 {
+		targets = []string{"."}
+	}
+
+	exit := 0
+	var diags []Diagnostic
+	for _, target := range targets {
+		files, err := lintTargetFiles(target)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "barry: %s\n", err)
+			exit = 1
+			continue
+		}
+		var moduleFiles []*parsedFile
+		for _, file := range files {
+			if *fix {
+				for _, rule := range LintRules {
+					if rule.Fix == nil || !lintRuleEnabled(rule.ID) {
+						continue
+					}
+					if err := rule.Fix(file); err != nil {
+						fmt.Fprintf(os.Stderr, "barry: %s: %s\n", file, err)
+						exit = 1
+					}
+				}
+			}
+			pf, fileDiags, err := lintFile(file)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "barry: %s: %s\n", file, err)
+				exit = 1
+				continue
+			}
+			diags = append(diags, fileDiags...)
+			if pf != nil {
+				moduleFiles = append(moduleFiles, pf)
+			}
+		}
+		if *fix {
+			for _, rule := range ModuleLintRules {
+				if rule.Fix == nil || !lintRuleEnabled(rule.ID) {
+					continue
+				}
+				changed, err := rule.Fix(moduleFiles)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "barry: %s\n", err)
+					exit = 1
+					continue
+				}
+				for _, path := range changed {
+					if pf, err := parseSyntaxFile(path); err == nil {
+						for i := range moduleFiles {
+							if moduleFiles[i].path == path {
+								moduleFiles[i] = pf
+							}
+						}
+					}
+				}
+			}
+		}
+		for _, rule := range ModuleLintRules {
+			if lintRuleEnabled(rule.ID) {
+				diags = append(diags, rule.Check(moduleFiles)...)
+			}
+		}
+	}
+	sort.SliceStable(diags, func(i, j int) bool {
+		if diags[i].File != diags[j].File {
+			return diags[i].File < diags[j].File
+		}
+		if diags[i].Line != diags[j].Line {
+			return diags[i].Line < diags[j].Line
+		}
+		return diags[i].Column < diags[j].Column
+	})
+	if *jsonOut {
+		out, _ := json.MarshalIndent(diags, "", "  ")
+		fmt.Println(string(out))
+	} else {
+		for _, d := range diags {
+			fmt.Printf("%s:%d:%d: %s (%s)\n", d.File, d.Line, d.Column, d.Message, d.Rule)
+		}
+	}
+	if exit == 0 && len(diags) > 0 {
+		exit = 2
+	}
+	return exit
+}
+
+// lintTargetFiles resolves a lint target to the files beneath it;
+// directories are always walked recursively.
+func lintTargetFiles(target string) ([]string, error) {
+	info, err := os.Stat(target)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return []string{target}, nil
+	}
+	saved := opts.recursive
+	opts.recursive = true
+	defer func() { opts.recursive = saved }()
+	return collectFiles(target)
+}
+
+// parseSyntaxFile parses a single file into its syntax body.
+func parseSyntaxFile(path string) (*parsedFile, error) {
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	f, parseDiags := hclsyntax.ParseConfig(src, path, hcl.InitialPos)
+	if parseDiags.HasErrors() {
+		return nil, parseDiags
+	}
+	body, ok := f.Body.(*hclsyntax.Body)
+	if !ok {
+		return nil, nil
+	}
+	return &parsedFile{path: path, body: body}, nil
+}
+
+// lintFile parses one file and runs every enabled lint rule over it,
+// also returning the parsed form for the module-wide rules.
+func lintFile(path string) (*parsedFile, []Diagnostic, error) {
+	pf, err := parseSyntaxFile(path)
+	if err != nil || pf == nil {
+		return nil, nil, err
+	}
+	path, body := pf.path, pf.body
+	var diags []Diagnostic
+	for _, rule := range LintRules {
+		if !lintRuleEnabled(rule.ID) {
+			continue
+		}
+		diags = append(diags, rule.Check(path, body)...)
+	}
+	if cfg.PluginDir != "" || cfg.PolicyDir != "" {
+		src, err := os.ReadFile(path)
+		if err != nil {
+			return pf, diags, err
+		}
+		if cfg.PluginDir != "" {
+			_, pluginDiags, err := runPlugins(path, src)
+			if err != nil {
+				return pf, diags, err
+			}
+			diags = append(diags, pluginDiags...)
+		}
+		if cfg.PolicyDir != "" {
+			policyDiags, err := runPolicyChecks(path, src, body)
+			if err != nil {
+				return pf, diags, err
+			}
+			diags = append(diags, policyDiags...)
+		}
+	}
+	return pf, diags, nil
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// missingDescriptionRule reports variable and output blocks that lack a
+// description attribute. The autofix inserts a templated placeholder so
+// the gap at least becomes visible in review.
+var missingDescriptionRule = &LintRule{
+	ID:          "missing_description",
+	Description: "variable and output blocks should have a description",
+	Check: func(path string, body *hclsyntax.Body) []Diagnostic {
+		var diags []Diagnostic
+		for _, block := range body.Blocks {
+			if block.Type != "variable" && block.Type != "output" {
+				continue
+			}
+			if _, ok := block.Body.Attributes["description"]; ok {
+				continue
+			}
+			label := ""
+			if len(block.Labels) > 0 {
+				label = block.Labels[0]
+			}
+			diags = append(diags, Diagnostic{
+				Rule:    "missing_description",
+				File:    path,
+				Line:    block.TypeRange.Start.Line,
+				Column:  block.TypeRange.Start.Column,
+				Message: fmt.Sprintf("%s %q has no description", block.Type, label),
+			})
+		}
+		return diags
+	},
+	Fix: fixMissingDescriptions,
+}
+
+func fixMissingDescriptions(path string) error {
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	f, diags := hclwrite.ParseConfig(src, path, hcl.InitialPos)
+	if diags.HasErrors() {
+		return diags
+	}
+	changed := false
+	for _, block := range f.Body().Blocks() {
+		if block.Type() != "variable" && block.Type() != "output" {
+			continue
+		}
+		if block.Body().GetAttribute("description") != nil {
+			continue
+		}
+		label := ""
+		if labels := block.Labels(); len(labels) > 0 {
+			label = labels[0]
+		}
+		placeholder := fmt.Sprintf("TODO: describe %s %s", block.Type(), label)
+		block.Body().SetAttributeValue("description", cty.StringVal(placeholder))
+		changed = true
+	}
+	if !changed {
+		return nil
+	}
+	return os.WriteFile(path, postProcess(f.Bytes(), true), 0644)
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+)
+
+// duplicateAddressRule reports resource, data, variable, output, module
+// and local addresses defined more than once across a module's files.
+// Within one file parsing already fails; across files the clash only
+// surfaces at terraform validate time, which is much later than it needs
+// to be.
+var duplicateAddressRule = &ModuleLintRule{
+	ID:          "duplicate_addresses",
+	Description: "addresses must be unique across a module directory",
+	Check: func(files []*parsedFile) []Diagnostic {
+		seen := make(map[string]hcl.Range)
+		var diags []Diagnostic
+		report := func(addr string, rng hcl.Range) {
+			first, ok := seen[addr]
+			if !ok {
+				seen[addr] = rng
+				return
+			}
+			diags = append(diags, Diagnostic{
+				Rule:   "duplicate_addresses",
+				File:   rng.Filename,
+				Line:   rng.Start.Line,
+				Column: rng.Start.Column,
+				Message: fmt.Sprintf("duplicate address %s; first defined at %s:%d",
+					addr, first.Filename, first.Start.Line),
+			})
+		}
+		for _, pf := range files {
+			if !strings.HasSuffix(pf.path, ".tf") {
+				continue
+			}
+			for _, block := range pf.body.Blocks {
+				if block.Type == "locals" {
+					for name, attr := range block.Body.Attributes {
+						report("local."+name, attr.NameRange)
+					}
+					continue
+				}
+				if addr := blockAddress(block.Type, block.Labels); addr != "" {
+					report(addr, block.DefRange())
+				}
+			}
+		}
+		return diags
+	},
+}
+
+// blockAddress returns the module-level address a top-level block
+// defines, or "" for block types that do not define one.
+func blockAddress(blockType string, labels []string) string {
+	switch blockType {
+	case "resource", "ephemeral":
+		if len(labels) == 2 {
+			return labels[0] + "." + labels[1]
+		}
+	case "data":
+		if len(labels) == 2 {
+			return "data." + labels[0] + "." + labels[1]
+		}
+	case "variable":
+		if len(labels) == 1 {
+			return "var." + labels[0]
+		}
+	case "output":
+		if len(labels) == 1 {
+			return "output." + labels[0]
+		}
+	case "module":
+		if len(labels) == 1 {
+			return "module." + labels[0]
+		}
+	}
+	return ""
+}
@@ -0,0 +1,197 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+)
+
+// defaultNamingPattern is the name shape enforced when naming_pattern is
+// not configured.
+const defaultNamingPattern = `^[a-z][a-z0-9_]*$`
+
+func namingPattern() *regexp.Regexp {
+	if cfg.NamingPattern != "" {
+		if re, err := regexp.Compile(cfg.NamingPattern); err == nil {
+			return re
+		}
+	}
+	return regexp.MustCompile(defaultNamingPattern)
+}
+
+// namedLabel returns the label that names a block of the given type, and
+// whether the block type participates in naming checks at all.
+func namedLabel(blockType string, labels []string) (string, bool) {
+	switch blockType {
+	case "resource", "data", "ephemeral":
+		if len(labels) == 2 {
+			return labels[1], true
+		}
+	case "variable", "output", "module":
+		if len(labels) == 1 {
+			return labels[0], true
+		}
+	}
+	return "", false
+}
+
+// namingRule checks that resource, variable, output and module names
+// match the configured pattern. The autofix renames within the file,
+// updating references to the renamed object.
+var namingRule = &LintRule{
+	ID:          "naming_convention",
+	Description: "resource, variable and output names should match the configured pattern",
+	Check: func(path string, body *hclsyntax.Body) []Diagnostic {
+		re := namingPattern()
+		var diags []Diagnostic
+		for _, block := range body.Blocks {
+			name, ok := namedLabel(block.Type, block.Labels)
+			if !ok || re.MatchString(name) {
+				continue
+			}
+			diags = append(diags, Diagnostic{
+				Rule:    "naming_convention",
+				File:    path,
+				Line:    block.TypeRange.Start.Line,
+				Column:  block.TypeRange.Start.Column,
+				Message: fmt.Sprintf("%s name %q does not match pattern %s", block.Type, name, re.String()),
+			})
+		}
+		return diags
+	},
+	Fix: fixNamingConventions,
+}
+
+func fixNamingConventions(path string) error {
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	f, diags := hclwrite.ParseConfig(src, path, hcl.InitialPos)
+	if diags.HasErrors() {
+		return diags
+	}
+	re := namingPattern()
+	var renames []rename
+	for _, block := range f.Body().Blocks() {
+		labels := block.Labels()
+		name, ok := namedLabel(block.Type(), labels)
+		if !ok || re.MatchString(name) {
+			continue
+		}
+		candidate := conventionalName(name)
+		if candidate == name || !re.MatchString(candidate) {
+			continue
+		}
+		labels[len(labels)-1] = candidate
+		block.SetLabels(labels)
+		switch block.Type() {
+		case "variable":
+			renames = append(renames, rename{path: []string{"var", name}, newName: candidate})
+		case "resource", "ephemeral":
+			renames = append(renames, rename{path: []string{labels[0], name}, newName: candidate})
+		case "data":
+			renames = append(renames, rename{path: []string{"data", labels[0], name}, newName: candidate})
+		case "module":
+			renames = append(renames, rename{path: []string{"module", name}, newName: candidate})
+		}
+	}
+	if len(renames) == 0 {
+		return nil
+	}
+	renameInBody(f.Body(), renames)
+	return os.WriteFile(path, postProcess(f.Bytes(), true), 0644)
+}
+
+// rename records that the object at the dotted path now goes by newName;
+// the last path segment is the one being replaced.
+type rename struct {
+	path    []string
+	newName string
+}
+
+// renameInBody rewrites traversals matching any rename throughout the
+// body, recursing into nested blocks.
+func renameInBody(body *hclwrite.Body, renames []rename) {
+	for name, attr := range body.Attributes() {
+		toks := attr.Expr().BuildTokens(nil)
+		if renameInTokens(toks, renames) {
+			body.SetAttributeRaw(name, toks)
+		}
+	}
+	for _, block := range body.Blocks() {
+		renameInBody(block.Body(), renames)
+	}
+}
+
+func renameInTokens(toks hclwrite.Tokens, renames []rename) bool {
+	changed := false
+	for i := range toks {
+		if toks[i].Type != hclsyntax.TokenIdent {
+			continue
+		}
+		if i > 0 && toks[i-1].Type == hclsyntax.TokenDot {
+			continue // not the start of a traversal
+		}
+		for _, r := range renames {
+			if end, ok := matchTraversal(toks, i, r.path); ok {
+				toks[end].Bytes = []byte(r.newName)
+				changed = true
+				break
+			}
+		}
+	}
+	return changed
+}
+
+// matchTraversal reports whether the dotted identifier path starts at
+// index i, returning the index of its final identifier token.
+func matchTraversal(toks hclwrite.Tokens, i int, path []string) (int, bool) {
+	idx := i
+	for n, segment := range path {
+		if n > 0 {
+			if idx >= len(toks) || toks[idx].Type != hclsyntax.TokenDot {
+				return 0, false
+			}
+			idx++
+		}
+		if idx >= len(toks) || toks[idx].Type != hclsyntax.TokenIdent || string(toks[idx].Bytes) != segment {
+			return 0, false
+		}
+		if n < len(path)-1 {
+			idx++
+		}
+	}
+	return idx, true
+}
+
+// conventionalName converts a name to the default convention: lower
+// case, with underscores at word boundaries and in place of dashes.
+func conventionalName(name string) string {
+	var b strings.Builder
+	prevLower := false
+	for _, r := range name {
+		switch {
+		case r >= 'A' && r <= 'Z':
+			if prevLower {
+				b.WriteByte('_')
+			}
+			b.WriteRune(r - 'A' + 'a')
+			prevLower = false
+		case (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9'):
+			b.WriteRune(r)
+			prevLower = r >= 'a' && r <= 'z'
+		case r == '_' || r == '-':
+			b.WriteByte('_')
+			prevLower = false
+		default:
+			prevLower = false
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// tfvarsConsistencyRule compares the .tfvars files of a target with one
+// another and with the declared variables. Keys set in some environments
+// but not others, and keys that match no declared variable, are both
+// frequent sources of apply-time surprises.
+var tfvarsConsistencyRule = &ModuleLintRule{
+	ID:          "tfvars_consistency",
+	Description: "tfvars files should set consistent, declared keys",
+	Check: func(files []*parsedFile) []Diagnostic {
+		declared := make(map[string]bool)
+		keysByFile := make(map[string]map[string]bool)
+		var tfvarsFiles []string
+		for _, pf := range files {
+			if strings.HasSuffix(pf.path, ".tf") {
+				for _, block := range pf.body.Blocks {
+					if block.Type == "variable" && len(block.Labels) == 1 {
+						declared[block.Labels[0]] = true
+					}
+				}
+				continue
+			}
+			if !strings.HasSuffix(pf.path, ".tfvars") {
+				continue
+			}
+			keys := make(map[string]bool)
+			for name := range pf.body.Attributes {
+				keys[name] = true
+			}
+			keysByFile[pf.path] = keys
+			tfvarsFiles = append(tfvarsFiles, pf.path)
+		}
+		sort.Strings(tfvarsFiles)
+
+		var diags []Diagnostic
+		if len(declared) > 0 {
+			for _, path := range tfvarsFiles {
+				for _, pf := range files {
+					if pf.path != path {
+						continue
+					}
+					for name, attr := range pf.body.Attributes {
+						if !declared[name] {
+							diags = append(diags, Diagnostic{
+								Rule:    "tfvars_consistency",
+								File:    path,
+								Line:    attr.NameRange.Start.Line,
+								Column:  attr.NameRange.Start.Column,
+								Message: fmt.Sprintf("%q does not match any declared variable", name),
+							})
+						}
+					}
+				}
+			}
+		}
+		if len(tfvarsFiles) < 2 {
+			return diags
+		}
+		union := make(map[string][]string)
+		for _, path := range tfvarsFiles {
+			for key := range keysByFile[path] {
+				union[key] = append(union[key], path)
+			}
+		}
+		var keys []string
+		for key := range union {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if len(union[key]) == len(tfvarsFiles) {
+				continue
+			}
+			for _, path := range tfvarsFiles {
+				if !keysByFile[path][key] {
+					diags = append(diags, Diagnostic{
+						Rule:   "tfvars_consistency",
+						File:   path,
+						Line:   1,
+						Column: 1,
+						Message: fmt.Sprintf("key %q is set in %s but not here",
+							key, strings.Join(union[key], ", ")),
+					})
+				}
+			}
+		}
+		return diags
+	},
+}
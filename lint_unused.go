@@ -0,0 +1,129 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+)
+
+// unusedDefinitionsRule builds a reference index over a module's files
+// and reports variable and locals definitions nothing refers to. The
+// fix removes them.
+var unusedDefinitionsRule = &ModuleLintRule{
+	ID:          "unused_definitions",
+	Description: "variables and locals should be referenced somewhere in the module",
+	Check: func(files []*parsedFile) []Diagnostic {
+		used := moduleReferences(files)
+		var diags []Diagnostic
+		for _, pf := range files {
+			if !strings.HasSuffix(pf.path, ".tf") {
+				continue
+			}
+			for _, block := range pf.body.Blocks {
+				switch block.Type {
+				case "variable":
+					if len(block.Labels) == 1 && !used["var."+block.Labels[0]] {
+						diags = append(diags, unusedDiag(pf.path, block.DefRange(),
+							fmt.Sprintf("variable %q is never referenced", block.Labels[0])))
+					}
+				case "locals":
+					for name, attr := range block.Body.Attributes {
+						if !used["local."+name] {
+							diags = append(diags, unusedDiag(pf.path, attr.NameRange,
+								fmt.Sprintf("local %q is never referenced", name)))
+						}
+					}
+				}
+			}
+		}
+		return diags
+	},
+	Fix: fixUnusedDefinitions,
+}
+
+func unusedDiag(path string, rng hcl.Range, message string) Diagnostic {
+	return Diagnostic{
+		Rule:    "unused_definitions",
+		File:    path,
+		Line:    rng.Start.Line,
+		Column:  rng.Start.Column,
+		Message: message,
+	}
+}
+
+// moduleReferences indexes every var.x and local.x traversal across the
+// module's expressions.
+func moduleReferences(files []*parsedFile) map[string]bool {
+	used := make(map[string]bool)
+	var walk func(b *hclsyntax.Body)
+	walk = func(b *hclsyntax.Body) {
+		for _, attr := range b.Attributes {
+			for _, trav := range attr.Expr.Variables() {
+				if len(trav) < 2 {
+					continue
+				}
+				if step, ok := trav[1].(hcl.TraverseAttr); ok {
+					used[trav.RootName()+"."+step.Name] = true
+				}
+			}
+		}
+		for _, block := range b.Blocks {
+			walk(block.Body)
+		}
+	}
+	for _, pf := range files {
+		walk(pf.body)
+	}
+	return used
+}
+
+func fixUnusedDefinitions(files []*parsedFile) ([]string, error) {
+	used := moduleReferences(files)
+	var changed []string
+	for _, pf := range files {
+		if !strings.HasSuffix(pf.path, ".tf") {
+			continue
+		}
+		src, err := os.ReadFile(pf.path)
+		if err != nil {
+			return changed, err
+		}
+		f, diags := hclwrite.ParseConfig(src, pf.path, hcl.InitialPos)
+		if diags.HasErrors() {
+			return changed, diags
+		}
+		body := f.Body()
+		fileChanged := false
+		for _, block := range body.Blocks() {
+			switch block.Type() {
+			case "variable":
+				labels := block.Labels()
+				if len(labels) == 1 && !used["var."+labels[0]] {
+					body.RemoveBlock(block)
+					fileChanged = true
+				}
+			case "locals":
+				for name := range block.Body().Attributes() {
+					if !used["local."+name] {
+						block.Body().RemoveAttribute(name)
+						fileChanged = true
+					}
+				}
+				if len(block.Body().Attributes()) == 0 && len(block.Body().Blocks()) == 0 {
+					body.RemoveBlock(block)
+				}
+			}
+		}
+		if fileChanged {
+			if err := os.WriteFile(pf.path, postProcess(f.Bytes(), true), 0644); err != nil {
+				return changed, err
+			}
+			changed = append(changed, pf.path)
+		}
+	}
+	return changed, nil
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+// sortLocalsSections sorts locals blocks section by section on the
+// rendered output. A definition and the comment lines directly above it
+// move as one unit; blank lines and standalone comments (the
+// "# Networking" headers, blank-separated from any definition) divide
+// sections and stay exactly where they are, which the tree-level
+// rebuild could not guarantee: hclwrite keeps standalone comments as
+// unstructured body tokens that cannot be repositioned. Reordering is a
+// pure line permutation, so heredoc bytes and line counts are
+// untouched.
+func sortLocalsSections(src []byte) []byte {
+	f, diags := hclsyntax.ParseConfig(src, "locals-sections", hcl.InitialPos)
+	if diags.HasErrors() {
+		return src
+	}
+	body, ok := f.Body.(*hclsyntax.Body)
+	if !ok {
+		return src
+	}
+	lines := splitLines(src)
+	for _, block := range body.Blocks {
+		if block.Type != "locals" {
+			continue
+		}
+		first := block.OpenBraceRange.End.Line + 1
+		last := block.CloseBraceRange.Start.Line - 1
+		if first > last || last > len(lines) {
+			continue
+		}
+		sortLocalsBody(lines, first, last, block.Body)
+	}
+	var out []byte
+	for _, line := range lines {
+		out = append(out, line...)
+		out = append(out, '\n')
+	}
+	return out
+}
+
+// sortLocalsBody permutes lines[first-1:last] (1-based, inclusive) so
+// each blank-delimited section's definitions are alphabetized.
+func sortLocalsBody(lines []string, first, last int, body *hclsyntax.Body) {
+	// Map each line to the definition whose span covers it, with the
+	// span extended upward over directly adjacent comment lines.
+	type span struct {
+		name       string
+		start, end int
+	}
+	var spans []span
+	for name, attr := range body.Attributes {
+		s := span{name: name, start: attr.SrcRange.Start.Line, end: attr.SrcRange.End.Line}
+		for s.start > first {
+			above := strings.TrimSpace(lines[s.start-2])
+			if !strings.HasPrefix(above, "#") && !strings.HasPrefix(above, "//") {
+				break
+			}
+			s.start--
+		}
+		spans = append(spans, s)
+	}
+	sort.Slice(spans, func(i, j int) bool { return spans[i].start < spans[j].start })
+	spanAt := func(line int) *span {
+		for i := range spans {
+			if line >= spans[i].start && line <= spans[i].end {
+				return &spans[i]
+			}
+		}
+		return nil
+	}
+
+	type unit struct {
+		name  string
+		lines []string
+	}
+	var out []string
+	var section []unit
+	flush := func() {
+		sort.SliceStable(section, func(i, j int) bool { return section[i].name < section[j].name })
+		for _, u := range section {
+			out = append(out, u.lines...)
+		}
+		section = nil
+	}
+	for line := first; line <= last; {
+		text := lines[line-1]
+		if s := spanAt(line); s != nil {
+			u := unit{name: s.name}
+			for ; line <= s.end && line <= last; line++ {
+				u.lines = append(u.lines, lines[line-1])
+			}
+			section = append(section, u)
+			continue
+		}
+		// A blank line or a standalone header comment ends the section
+		// and keeps its own position.
+		flush()
+		out = append(out, text)
+		line++
+	}
+	flush()
+	if len(out) != last-first+1 {
+		return // a span fell outside the body; leave the block alone
+	}
+	copy(lines[first-1:last], out)
+}
@@ -0,0 +1,691 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+)
+
+type options struct {
+	check          bool
+	diff           bool
+	recursive      bool
+	write          bool
+	sortBlocks     bool
+	fixLegacy      bool
+	configFile     string
+	format         string
+	watch          bool
+	metricsAddr    string
+	stdoutMode     bool
+	filesFrom      string
+	nulInput       bool
+	print0         bool
+	followMods     bool
+	fragment       bool
+	upgradeLegacy  bool
+	dialect        string
+	diffContext    int
+	diffMode       string
+	stats          bool
+	statsJSON      bool
+	changedBlocks  bool
+	verbose        bool
+	workspace      string
+	maxUnformatted int
+	maxChanges     int
+	since          string
+	commit         bool
+	commitMessage  string
+	exitZero       bool
+	quiet          bool
+	schemaFile     string
+	stdinFilepath  string
+}
+
+var opts options
+
+func init() {
+	flag.BoolVar(&opts.check, "check", false, "check whether the input is formatted without writing any changes")
+	flag.BoolVar(&opts.diff, "diff", false, "display diffs of formatting changes instead of writing them")
+	flag.IntVar(&opts.diffContext, "diff-context", 3, "context lines shown around each change in -diff output")
+	flag.StringVar(&opts.diffMode, "diff-mode", "unified", "diff layout: unified or side-by-side")
+	flag.BoolVar(&opts.recursive, "recursive", false, "also process files in subdirectories")
+	flag.BoolVar(&opts.write, "write", true, "write the result to the source file instead of stdout")
+	flag.BoolVar(&opts.sortBlocks, "sort", false, "sort top-level blocks by type and label")
+	flag.BoolVar(&opts.fixLegacy, "fix-legacy", false, "enable the pre-0.12 expression upgrade rules as a set")
+	flag.BoolVar(&opts.upgradeLegacy, "upgrade-legacy", false, "aggressively upgrade pre-0.12 constructs and report what could not be rewritten")
+	flag.StringVar(&opts.configFile, "config", "", "path to a config file (defaults to .barry.hcl if present)")
+	flag.StringVar(&opts.format, "format", "text", "output format: text, html, markdown, edits or json-diff (non-text formats imply check mode)")
+	flag.BoolVar(&opts.stdoutMode, "stdout", false, "print the formatted content of a single file target instead of writing in place")
+	flag.StringVar(&opts.filesFrom, "files-from", "", "read target paths from this file (\"-\" for stdin)")
+	flag.BoolVar(&opts.nulInput, "0", false, "treat the -files-from list as NUL-delimited (as produced by git diff -z)")
+	flag.BoolVar(&opts.print0, "print0", false, "NUL-terminate the changed-file list in check mode, for xargs -0 pipelines")
+	flag.BoolVar(&opts.followMods, "follow-modules", false, "also process the local module directories the targets reference")
+	flag.BoolVar(&opts.fragment, "fragment", false, "format an HCL snippet from stdin (or one file) that need not be a valid top-level file")
+	flag.BoolVar(&opts.watch, "watch", false, "keep running and reformat files as they change")
+	flag.StringVar(&opts.metricsAddr, "metrics-addr", "", "serve Prometheus metrics on this address in watch mode")
+	flag.StringVar(&opts.dialect, "dialect", dialectTerraform, "configuration dialect: terraform or opentofu")
+	flag.BoolVar(&opts.stats, "stats", false, "report how many times each rule changed something")
+	flag.BoolVar(&opts.changedBlocks, "changed-blocks", false, "in check mode, list the block addresses that would change within each file")
+	flag.BoolVar(&opts.verbose, "verbose", false, "in check mode, list the rule IDs that would change each file")
+	flag.StringVar(&opts.workspace, "workspace", "", "process every root listed in this workspace manifest")
+	flag.IntVar(&opts.maxUnformatted, "max-unformatted", 0, "check mode only fails when more than this many files are unformatted")
+	flag.BoolVar(&opts.exitZero, "exit-zero-on-changes", false, "exit 0 even when check or diff finds differences, reserving non-zero for errors")
+	flag.BoolVar(&opts.quiet, "quiet", false, "print nothing and communicate purely via the exit code")
+	flag.BoolVar(&opts.quiet, "q", false, "shorthand for -quiet")
+	flag.IntVar(&opts.maxChanges, "max-changes", 0, "rewrite at most this many files per run, reporting the remainder (0 = unlimited)")
+	flag.StringVar(&opts.since, "since", "", "only enforce on files last committed at or after this date or git ref")
+	flag.StringVar(&opts.schemaFile, "schema-file", "", "terraform providers schema -json output enabling schema-aware attribute ordering")
+	flag.StringVar(&opts.stdinFilepath, "stdin-filepath", "", "logical filename for content read from stdin, choosing which rules apply")
+	flag.BoolVar(&opts.commit, "commit", false, "git-commit the files barry rewrote, refusing if unrelated changes are staged")
+	flag.StringVar(&opts.commitMessage, "m", "barry: reformat", "commit message used with -commit")
+	flag.BoolVar(&opts.statsJSON, "stats-json", false, "emit the -stats report as JSON")
+}
+
+func main() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "organize":
+			os.Exit(runOrganize(os.Args[2:]))
+		case "lint":
+			os.Exit(runLint(os.Args[2:]))
+		case "tflint-ruleset":
+			runTflintRuleset()
+			return
+		case "atlantis":
+			os.Exit(runAtlantis(os.Args[2:]))
+		case "suggest":
+			os.Exit(runSuggest(os.Args[2:]))
+		case "serve":
+			os.Exit(runServe(os.Args[2:]))
+		case "version":
+			os.Exit(runVersion(os.Args[2:]))
+		case "migrate-style":
+			os.Exit(runMigrateStyle(os.Args[2:]))
+		case "audit-modules":
+			os.Exit(runAuditModules(os.Args[2:]))
+		case "inspect":
+			os.Exit(runInspect(os.Args[2:]))
+		case "testdata":
+			os.Exit(runTestdata(os.Args[2:]))
+		case "doctor":
+			os.Exit(runDoctor(os.Args[2:]))
+		case "init":
+			os.Exit(runInit(os.Args[2:]))
+		case "config":
+			os.Exit(runConfigCmd(os.Args[2:]))
+		case "pr":
+			os.Exit(runPR(os.Args[2:]))
+		}
+	}
+	flag.Parse()
+	if err := validDialect(opts.dialect); err != nil {
+		fmt.Fprintf(os.Stderr, "barry: %s\n", err)
+		os.Exit(1)
+	}
+	var err error
+	cfg, err = loadConfig(opts.configFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "barry: %s\n", err)
+		os.Exit(1)
+	}
+	if opts.schemaFile != "" {
+		activeSchemas, err = loadProviderSchemas(opts.schemaFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "barry: %s\n", err)
+			os.Exit(1)
+		}
+	}
+	if opts.fragment {
+		os.Exit(runFragment(flag.Args()))
+	}
+	if opts.workspace != "" {
+		os.Exit(runWorkspace(opts.workspace))
+	}
+	if opts.format != "text" {
+		report = &runReport{}
+	}
+	targets := flag.Args()
+	if opts.filesFrom != "" {
+		listed, err := readFilesFrom(opts.filesFrom, opts.nulInput)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "barry: %s\n", err)
+			os.Exit(1)
+		}
+		targets = append(targets, listed...)
+	}
+	if len(targets) == 0 {
+		targets = []string{"."}
+	}
+	if len(targets) == 1 && targets[0] == "-" {
+		os.Exit(runStdin())
+	}
+	if opts.followMods {
+		targets = expandModuleTargets(targets)
+	}
+	if opts.watch {
+		os.Exit(runWatch(targets))
+	}
+	os.Exit(run(targets))
+}
+
+// Write-cap bookkeeping for -max-changes: files are processed in the
+// deterministic walk order, so successive runs continue where the last
+// one stopped.
+var (
+	writesDone     int
+	writesDeferred int
+	writtenFiles   []string
+)
+
+func run(targets []string) int {
+	if opts.stdoutMode {
+		if len(targets) != 1 {
+			fmt.Fprintln(os.Stderr, "barry: -stdout takes exactly one file target")
+			return 1
+		}
+		if info, err := os.Stat(targets[0]); err == nil && info.IsDir() {
+			fmt.Fprintln(os.Stderr, "barry: -stdout takes a file, not a directory")
+			return 1
+		}
+	}
+	var cutoff time.Time
+	if opts.since != "" {
+		var err error
+		cutoff, err = ratchetCutoff(opts.since)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "barry: %s\n", err)
+			return 1
+		}
+	}
+	exit := 0
+	changedCount := 0
+	for _, target := range targets {
+		info, err := os.Stat(target)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "barry: %s\n", err)
+			exit = 1
+			continue
+		}
+		files := []string{target}
+		if info.IsDir() {
+			files, err = collectFiles(target)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "barry: %s\n", err)
+				exit = 1
+				continue
+			}
+		}
+		if opts.since != "" {
+			files = filterTouchedSince(files, cutoff)
+		}
+		for _, file := range files {
+			changed, err := processFile(file)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "barry: %s: %s\n", file, err)
+				exit = 1
+				continue
+			}
+			if changed {
+				changedCount++
+			}
+		}
+	}
+	if changedCount > 0 && (opts.check || report != nil) && exit == 0 {
+		switch {
+		case changedCount <= opts.maxUnformatted:
+			if !opts.quiet {
+				fmt.Fprintf(os.Stderr, "barry: %d unformatted file(s), within the budget of %d\n",
+					changedCount, opts.maxUnformatted)
+			}
+		case opts.exitZero:
+			// Differences found, but this run only reports.
+		default:
+			exit = 2
+		}
+	}
+	if report != nil {
+		if err := renderReport(report); err != nil {
+			fmt.Fprintf(os.Stderr, "barry: %s\n", err)
+			exit = 1
+		}
+	}
+	if opts.commit && exit != 1 {
+		if err := gitCommitChanges(writtenFiles, opts.commitMessage); err != nil {
+			fmt.Fprintf(os.Stderr, "barry: %s\n", err)
+			exit = 1
+		}
+	}
+	if writesDeferred > 0 {
+		fmt.Fprintf(os.Stderr, "barry: stopped after %d change(s); %d more file(s) still need formatting\n",
+			writesDone, writesDeferred)
+	}
+	for _, warning := range upgradeWarnings {
+		fmt.Fprintf(os.Stderr, "barry: upgrade: %s\n", warning)
+	}
+	if opts.stats || opts.statsJSON {
+		printRuleStats(os.Stdout, opts.statsJSON)
+	}
+	return exit
+}
+
+// renderReport writes the collected run report in the requested format.
+func renderReport(rep *runReport) error {
+	switch opts.format {
+	case "html":
+		return renderHTMLReport(os.Stdout, rep)
+	case "markdown":
+		return renderMarkdownReport(os.Stdout, rep)
+	case "edits":
+		return renderEditsReport(os.Stdout, rep)
+	case "json-diff":
+		return renderJSONDiffReport(os.Stdout, rep)
+	default:
+		return fmt.Errorf("unknown output format %q", opts.format)
+	}
+}
+
+// readFilesFrom reads target paths from a list file ("-" for stdin),
+// one per line, or NUL-delimited when nul is set so that
+// git diff --name-only -z pipes straight in without quoting trouble.
+func readFilesFrom(path string, nul bool) ([]string, error) {
+	var src []byte
+	var err error
+	if path == "-" {
+		src, err = io.ReadAll(os.Stdin)
+	} else {
+		src, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return nil, err
+	}
+	sep := "\n"
+	if nul {
+		sep = "\x00"
+	}
+	var targets []string
+	for _, entry := range strings.Split(string(src), sep) {
+		if !nul {
+			entry = strings.TrimSpace(entry)
+		}
+		if entry != "" {
+			targets = append(targets, entry)
+		}
+	}
+	return targets, nil
+}
+
+// collectFiles gathers the formattable files beneath dir, honouring the
+// recursive flag and skipping .git and .terraform directories.
+func collectFiles(dir string) ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path == dir {
+				return nil
+			}
+			if !opts.recursive || d.Name() == ".git" || d.Name() == ".terraform" {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if hasSupportedExt(d.Name()) {
+			files = append(files, path)
+		}
+		return nil
+	})
+	return files, err
+}
+
+func processFile(path string) (changed bool, err error) {
+	// A formatter bug must never destroy user code mid-run: any panic
+	// below is caught before a single byte is written, recorded to a
+	// crash report, and the run continues with the next file.
+	defer func() {
+		if r := recover(); r != nil {
+			changed = false
+			err = writeCrashReport(path, r)
+		}
+	}()
+	src, err := os.ReadFile(longPath(path))
+	if err != nil {
+		return false, err
+	}
+	var snap map[string]int
+	if opts.check && opts.verbose {
+		snap = rulesSnapshot()
+	}
+	out, err := formatSource(path, src)
+	if err != nil && fileKind(path) == "tf" && ruleEnabled("partial_format") {
+		if salvaged, ok := formatPartial(path, src); ok {
+			out, err = salvaged, nil
+		}
+	}
+	if report != nil {
+		res := fileResult{Path: path, Before: src, After: out}
+		if err != nil {
+			res.Err = err.Error()
+		} else {
+			res.Changed = !bytes.Equal(src, out)
+		}
+		report.add(res)
+		return res.Changed, err
+	}
+	if err != nil {
+		return false, err
+	}
+	if opts.stdoutMode {
+		// -stdout always prints, changed or not, so callers can pipe the
+		// result somewhere regardless.
+		os.Stdout.Write(out)
+		return !bytes.Equal(src, out), nil
+	}
+	if bytes.Equal(src, out) {
+		return false, nil
+	}
+	switch {
+	case opts.check:
+		if opts.quiet {
+			break
+		}
+		if opts.print0 {
+			fmt.Printf("%s\x00", displayPath(path))
+		} else {
+			fmt.Println(displayPath(path))
+			if opts.changedBlocks {
+				printChangedBlocks(src, out, path)
+			}
+			if opts.verbose {
+				ids := rulesChangedSince(snap)
+				if len(ids) == 0 {
+					// Ordering and whitespace normalization are not
+					// togglable rules but still produce changes.
+					ids = []string{"base_formatting"}
+				}
+				for _, id := range ids {
+					fmt.Printf("  - %s\n", id)
+				}
+			}
+		}
+	case opts.diff:
+		if opts.quiet {
+			break
+		}
+		fmt.Println(displayPath(path))
+		if err := showDiff(displayPath(path), src, out); err != nil {
+			return true, err
+		}
+	case opts.write:
+		if opts.maxChanges > 0 && writesDone >= opts.maxChanges {
+			writesDeferred++
+			return true, nil
+		}
+		if err := os.WriteFile(longPath(path), out, 0644); err != nil {
+			return true, err
+		}
+		writesDone++
+		writtenFiles = append(writtenFiles, path)
+	default:
+		os.Stdout.Write(out)
+	}
+	return true, nil
+}
+
+// formatSource formats the contents of a single file. The rules applied
+// depend on the file kind: tf-style files get the full ordering
+// treatment, tfvars files only syntactic cleanup.
+func formatSource(path string, src []byte) ([]byte, error) {
+	// CRLF files round-trip: the formatter works on LF internally and
+	// the original line endings come back at the end (unless an
+	// .editorconfig already decided them).
+	original := src
+	crlf := bytes.Contains(src, []byte("\r\n"))
+	if crlf {
+		src = bytes.ReplaceAll(src, []byte("\r\n"), []byte("\n"))
+	}
+	out, err := formatSourceLF(path, src)
+	if err != nil {
+		return nil, err
+	}
+	// The pipeline invariant: unless a heredoc-specific rule is
+	// explicitly enabled, no pass may change heredoc body bytes. A
+	// violation means a formatter bug, and the answer to a formatter bug
+	// is never corrupted user data: the file comes back unchanged.
+	if bytes.Contains(src, []byte("<<")) && !heredocRulesActive() && !heredocBodiesEqual(src, out) {
+		fmt.Fprintf(os.Stderr, "barry: internal error: %s: a formatting pass modified heredoc contents; file left unchanged\n", path)
+		return original, nil
+	}
+	if crlf && !bytes.Contains(out, []byte("\r\n")) {
+		out = bytes.ReplaceAll(out, []byte("\n"), []byte("\r\n"))
+	}
+	return out, nil
+}
+
+func formatSourceLF(path string, src []byte) ([]byte, error) {
+	prevType := activeFileType
+	activeFileType = fileTypeFor(path)
+	defer func() { activeFileType = prevType }()
+	switch fileKind(path) {
+	case "tf":
+		if bytes.Contains(src, []byte("barry:keep")) {
+			if out, ok := formatWithKeeps(path, src); ok {
+				if strings.HasSuffix(path, ".tf") {
+					out = applyHeader(out)
+				}
+				return applyEditorConfig(path, out), nil
+			}
+		}
+		f, diags := hclwrite.ParseConfig(src, path, hcl.InitialPos)
+		if diags.HasErrors() {
+			return nil, enrichParseError(src, diags)
+		}
+		formatFile(f)
+		out := postProcess(f.Bytes(), strings.HasSuffix(path, ".tf"))
+		if strings.HasSuffix(path, ".tf") {
+			out = applyHeader(out)
+		}
+		if cfg.PluginDir != "" {
+			pluginOut, _, err := runPlugins(path, out)
+			if err != nil {
+				return nil, err
+			}
+			out = pluginOut
+		}
+		return applyEditorConfig(path, out), nil
+	case "tfvars":
+		f, diags := hclwrite.ParseConfig(src, path, hcl.InitialPos)
+		if diags.HasErrors() {
+			return nil, enrichParseError(src, diags)
+		}
+		// tfvars files keep their author's ordering, but the
+		// attribute-level expression passes - collapsing, wrapping,
+		// barry:sort directives - apply to their top-level assignments.
+		applyExpressionPasses(f.Body(), "", 0)
+		return applyEditorConfig(path, postProcess(f.Bytes(), false)), nil
+	default:
+		return src, nil
+	}
+}
+
+var (
+	// slashCommentRe converts legacy C++-style comments to the idiomatic
+	// # form; it is applied line by line.
+	slashCommentRe = regexp.MustCompile(`^(\s*)//`)
+)
+
+// topLevelStartRe matches a line that begins a top-level block, for
+// blank-line separation.
+var topLevelStartRe struct {
+	mu  sync.Mutex
+	key string
+	re  *regexp.Regexp
+}
+
+func topLevelBlockRegexp() *regexp.Regexp {
+	key := strings.Join(topLevelBlockNames(), "|")
+	topLevelStartRe.mu.Lock()
+	defer topLevelStartRe.mu.Unlock()
+	if topLevelStartRe.re == nil || topLevelStartRe.key != key {
+		topLevelStartRe.re = regexp.MustCompile(`^(?:` + key + `)[ "]`)
+		topLevelStartRe.key = key
+	}
+	return topLevelStartRe.re
+}
+
+// postProcess applies the byte-level cleanups that are easier done on
+// rendered lines than on the syntax tree. Every pass works on lines
+// tagged with heredoc protection, lexed from the rendered output, so
+// none of them can modify heredoc body bytes. Top-level block
+// separation only applies to .tf files; a .tfvars attribute may
+// legitimately share a name with a block type.
+func postProcess(src []byte, topLevel bool) []byte {
+	lines := toRenderLines(hclwrite.Format(src))
+
+	if ruleEnabled("comment_conversion") {
+		for i, line := range lines {
+			if !line.protected {
+				lines[i].text = slashCommentRe.ReplaceAllString(line.text, "$1#")
+			}
+		}
+	}
+	if ruleEnabled("comment_spacing") {
+		lines = normalizeCommentSpacing(lines)
+	}
+	if ruleEnabled("reflow_comments") {
+		width := cfg.MaxLineLength
+		if width <= 0 {
+			width = 80
+		}
+		lines = reflowComments(lines, width)
+	}
+	lines = collapseBlankLines(lines)
+	lines = trimBraceHuggingBlanks(lines)
+	if topLevel {
+		lines = separateTopLevelBlocks(lines)
+	}
+
+	var out []byte
+	for _, line := range lines {
+		out = append(out, line.text...)
+		out = append(out, '\n')
+	}
+	if topLevel && ruleEnabled("locals_sections") {
+		out = sortLocalsSections(out)
+	}
+	return out
+}
+
+// collapseBlankLines keeps at most max_blank_lines consecutive
+// unprotected blank lines.
+func collapseBlankLines(lines []renderLine) []renderLine {
+	max := cfg.MaxBlankLines
+	if max < 1 {
+		max = 1
+	}
+	out := lines[:0]
+	blanks := 0
+	for _, line := range lines {
+		if !line.protected && strings.TrimSpace(line.text) == "" {
+			blanks++
+			if blanks > max {
+				continue
+			}
+		} else {
+			blanks = 0
+		}
+		out = append(out, line)
+	}
+	// Drop trailing blank lines entirely.
+	for len(out) > 0 && !out[len(out)-1].protected && strings.TrimSpace(out[len(out)-1].text) == "" {
+		out = out[:len(out)-1]
+	}
+	return out
+}
+
+// trimBraceHuggingBlanks removes blank lines directly after an opening
+// brace and directly before a closing one; body reordering can leave a
+// stray newline at the top of a rebuilt block.
+func trimBraceHuggingBlanks(lines []renderLine) []renderLine {
+	var out []renderLine
+	for _, line := range lines {
+		if !line.protected && strings.TrimSpace(line.text) == "" && len(out) > 0 {
+			prev := out[len(out)-1]
+			if !prev.protected && strings.HasSuffix(strings.TrimSpace(prev.text), "{") {
+				continue
+			}
+		}
+		if !line.protected && strings.HasPrefix(strings.TrimSpace(line.text), "}") {
+			for len(out) > 0 && !out[len(out)-1].protected && strings.TrimSpace(out[len(out)-1].text) == "" {
+				out = out[:len(out)-1]
+			}
+		}
+		out = append(out, line)
+	}
+	return out
+}
+
+// separateTopLevelBlocks inserts a blank line between a non-blank line
+// and a following top-level block. Full-line comments directly above a
+// block are its documentation and stay attached: the blank goes above
+// the comment run, never between the comments and the block.
+func separateTopLevelBlocks(lines []renderLine) []renderLine {
+	re := topLevelBlockRegexp()
+	isComment := func(line renderLine) bool {
+		trimmed := strings.TrimSpace(line.text)
+		return strings.HasPrefix(trimmed, "#") || strings.HasPrefix(trimmed, "//")
+	}
+	var out []renderLine
+	for i, line := range lines {
+		if i > 0 && !line.protected && re.MatchString(line.text) {
+			// Walk above any attached comment run to find the insertion
+			// point for the separating blank.
+			at := len(out)
+			for at > 0 && !out[at-1].protected && isComment(out[at-1]) {
+				at--
+			}
+			if at > 0 && !out[at-1].protected && strings.TrimSpace(out[at-1].text) != "" {
+				out = append(out[:at], append([]renderLine{{}}, out[at:]...)...)
+			}
+		}
+		out = append(out, line)
+	}
+	return out
+}
+
+// showDiff writes a diff of the change to stdout, honouring the
+// -diff-context and -diff-mode flags via the internal renderers.
+func showDiff(path string, before, after []byte) error {
+	var text string
+	switch opts.diffMode {
+	case "side-by-side":
+		text = renderSideBySideDiff(path, before, after, opts.diffContext)
+	case "unified":
+		text = renderUnifiedDiff(path, before, after, opts.diffContext)
+	default:
+		return fmt.Errorf("unknown diff mode %q", opts.diffMode)
+	}
+	_, err := os.Stdout.WriteString(text)
+	return err
+}
+
+// diffBytes produces a unified diff with default context through the
+// internal renderer; no external diff tool is involved, so the path
+// works on Windows too.
+func diffBytes(path string, before, after []byte) (string, error) {
+	return renderUnifiedDiff(path, before, after, 3), nil
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// metricsState holds the process-wide counters exposed in Prometheus
+// text format on /metrics in serve and watch modes. The exposition is
+// written by hand; the counter set is small enough that a client
+// library would outweigh it.
+type metricsState struct {
+	mu             sync.Mutex
+	filesProcessed int64
+	filesChanged   int64
+	errors         int64
+	latencySum     float64
+	latencyCount   int64
+	latencyBuckets map[float64]int64
+	rulesTriggered map[string]int64
+}
+
+// latencyBucketBounds are the upper bounds, in seconds, of the
+// formatting latency histogram.
+var latencyBucketBounds = []float64{0.001, 0.005, 0.01, 0.05, 0.1, 0.5, 1}
+
+var metrics = &metricsState{
+	latencyBuckets: make(map[float64]int64),
+	rulesTriggered: make(map[string]int64),
+}
+
+// observe records one formatting operation.
+func (m *metricsState) observe(elapsed time.Duration, changed bool, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.filesProcessed++
+	if changed {
+		m.filesChanged++
+	}
+	if err != nil {
+		m.errors++
+	}
+	secs := elapsed.Seconds()
+	m.latencySum += secs
+	m.latencyCount++
+	for _, bound := range latencyBucketBounds {
+		if secs <= bound {
+			m.latencyBuckets[bound]++
+		}
+	}
+}
+
+// ruleTriggered counts one application of a rule.
+func (m *metricsState) ruleTriggered(id string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.rulesTriggered[id]++
+}
+
+// write renders the Prometheus text exposition.
+func (m *metricsState) write(w io.Writer) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	fmt.Fprintln(w, "# TYPE barry_files_processed_total counter")
+	fmt.Fprintf(w, "barry_files_processed_total %d\n", m.filesProcessed)
+	fmt.Fprintln(w, "# TYPE barry_files_changed_total counter")
+	fmt.Fprintf(w, "barry_files_changed_total %d\n", m.filesChanged)
+	fmt.Fprintln(w, "# TYPE barry_errors_total counter")
+	fmt.Fprintf(w, "barry_errors_total %d\n", m.errors)
+	fmt.Fprintln(w, "# TYPE barry_rule_triggered_total counter")
+	ids := make([]string, 0, len(m.rulesTriggered))
+	for id := range m.rulesTriggered {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		fmt.Fprintf(w, "barry_rule_triggered_total{rule=%q} %d\n", id, m.rulesTriggered[id])
+	}
+	fmt.Fprintln(w, "# TYPE barry_format_duration_seconds histogram")
+	cumulative := int64(0)
+	for _, bound := range latencyBucketBounds {
+		cumulative = m.latencyBuckets[bound]
+		fmt.Fprintf(w, "barry_format_duration_seconds_bucket{le=\"%g\"} %d\n", bound, cumulative)
+	}
+	fmt.Fprintf(w, "barry_format_duration_seconds_bucket{le=\"+Inf\"} %d\n", m.latencyCount)
+	fmt.Fprintf(w, "barry_format_duration_seconds_sum %g\n", m.latencySum)
+	fmt.Fprintf(w, "barry_format_duration_seconds_count %d\n", m.latencyCount)
+}
+
+func handleMetrics(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	metrics.write(w)
+}
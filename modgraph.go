@@ -0,0 +1,99 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// expandModuleTargets implements -follow-modules: starting from the
+// directory targets, local module source paths are followed
+// breadth-first, so formatting a root configuration also covers every
+// module directory it actually uses. A visited set keeps module cycles
+// from looping.
+func expandModuleTargets(targets []string) []string {
+	var out []string
+	visited := make(map[string]bool)
+	queue := append([]string(nil), targets...)
+	for len(queue) > 0 {
+		target := queue[0]
+		queue = queue[1:]
+		abs, err := filepath.Abs(target)
+		if err != nil {
+			out = append(out, target)
+			continue
+		}
+		if visited[abs] {
+			continue
+		}
+		visited[abs] = true
+		out = append(out, target)
+		info, err := os.Stat(target)
+		if err != nil {
+			continue
+		}
+		if !info.IsDir() {
+			// A file target follows its module references too; sources
+			// resolve against the file's directory.
+			if strings.HasSuffix(target, ".tf") {
+				queue = append(queue, fileModuleSources(target, filepath.Dir(target))...)
+			}
+			continue
+		}
+		sources, err := moduleSources(target)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "barry: %s: %s\n", target, err)
+			continue
+		}
+		queue = append(queue, sources...)
+	}
+	return out
+}
+
+// moduleSources returns the local module directories referenced by the
+// .tf files directly in dir. Registry and remote sources are not local
+// directories and are skipped.
+func moduleSources(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var sources []string
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".tf") {
+			continue
+		}
+		sources = append(sources, fileModuleSources(filepath.Join(dir, entry.Name()), dir)...)
+	}
+	return sources, nil
+}
+
+// fileModuleSources returns the local module directories one file
+// references, resolved against baseDir. Unparseable files yield
+// nothing; they surface when they are formatted.
+func fileModuleSources(path, baseDir string) []string {
+	pf, err := parseSyntaxFile(path)
+	if err != nil || pf == nil {
+		return nil
+	}
+	var sources []string
+	for _, block := range pf.body.Blocks {
+		if block.Type != "module" {
+			continue
+		}
+		attr, ok := block.Body.Attributes["source"]
+		if !ok {
+			continue
+		}
+		val, diags := attr.Expr.Value(nil)
+		if diags.HasErrors() || !val.IsKnown() || val.Type().FriendlyName() != "string" {
+			continue
+		}
+		source := val.AsString()
+		if strings.HasPrefix(source, "./") || strings.HasPrefix(source, "../") {
+			sources = append(sources, filepath.Clean(filepath.Join(baseDir, source)))
+		}
+	}
+	return sources
+}
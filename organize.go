@@ -0,0 +1,109 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+)
+
+// organizeTarget returns the conventional file for a top-level block, or
+// "" for block types that stay in the file their author chose.
+func organizeTarget(block *hclwrite.Block) string {
+	switch block.Type() {
+	case "variable":
+		return "variables.tf"
+	case "output":
+		return "outputs.tf"
+	case "terraform":
+		return "versions.tf"
+	case "provider":
+		return "providers.tf"
+	}
+	return ""
+}
+
+// runOrganize implements the organize subcommand: an opt-in structural
+// cleanup that moves top-level blocks within a module directory into
+// their conventional files, creating or extending those files as
+// needed.
+func runOrganize(args []string) int {
+	fs := flag.NewFlagSet("organize", flag.ExitOnError)
+	fs.Parse(args)
+	dir := "."
+	if fs.NArg() > 0 {
+		dir = fs.Arg(0)
+	}
+	if err := organizeDir(dir); err != nil {
+		fmt.Fprintf(os.Stderr, "barry: %s\n", err)
+		return 1
+	}
+	return 0
+}
+
+func organizeDir(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	// Blocks are gathered per target file, with their attached comments,
+	// in a stable order: by source file, then source order.
+	moved := make(map[string][]byte)
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if !strings.HasSuffix(name, ".tf") {
+			continue
+		}
+		path := filepath.Join(dir, name)
+		src, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		f, diags := hclwrite.ParseConfig(src, path, hcl.InitialPos)
+		if diags.HasErrors() {
+			return fmt.Errorf("%s: %s", path, diags)
+		}
+		body := f.Body()
+		changed := false
+		for _, block := range body.Blocks() {
+			target := organizeTarget(block)
+			if target == "" || target == name {
+				continue
+			}
+			moved[target] = append(moved[target], block.BuildTokens(nil).Bytes()...)
+			body.RemoveBlock(block)
+			changed = true
+		}
+		if changed {
+			if err := os.WriteFile(path, postProcess(f.Bytes(), true), 0644); err != nil {
+				return err
+			}
+		}
+	}
+	for target, blocks := range moved {
+		path := filepath.Join(dir, target)
+		var content []byte
+		if existing, err := os.ReadFile(path); err == nil {
+			content = existing
+		} else if !os.IsNotExist(err) {
+			return err
+		}
+		if len(content) > 0 && content[len(content)-1] != '\n' {
+			content = append(content, '\n')
+		}
+		content = append(content, blocks...)
+		if err := os.WriteFile(path, postProcess(content, true), 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"regexp"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+)
+
+// chunkStartRe matches a line that opens a top-level block.
+var chunkStartRe = regexp.MustCompile(`^[a-z_][a-z0-9_]*[ "{]`)
+
+// formatPartial salvages a file whose parse failed by splitting it into
+// top-level chunks and formatting every chunk that parses on its own,
+// leaving the erroneous regions byte-for-byte intact with a warning.
+// WIP files in editors frequently have exactly one broken block; the
+// rest still deserves formatting. ok reports whether anything at all
+// could be formatted.
+func formatPartial(path string, src []byte) ([]byte, bool) {
+	chunks := splitTopLevelChunks(src)
+	var out bytes.Buffer
+	formatted := false
+	for _, chunk := range chunks {
+		if chunk.block {
+			f, diags := hclwrite.ParseConfig(chunk.text, path, hcl.InitialPos)
+			if !diags.HasErrors() {
+				formatFile(f)
+				out.Write(postProcess(f.Bytes(), true))
+				formatted = true
+				continue
+			}
+			fmt.Fprintf(os.Stderr, "barry: warning: %s: lines %d-%d left unformatted (parse error)\n",
+				path, chunk.startLine, chunk.endLine)
+		}
+		out.Write(chunk.text)
+	}
+	return out.Bytes(), formatted
+}
+
+// A srcChunk is a run of lines: either one top-level block (balanced on
+// column-zero braces) or the text between blocks.
+type srcChunk struct {
+	text      []byte
+	block     bool
+	startLine int
+	endLine   int
+}
+
+// splitTopLevelChunks cuts a possibly-broken source into block chunks
+// by brace counting. The counting is deliberately naive - a chunk that
+// does not parse standalone is left untouched anyway, so a miscount
+// only shrinks what gets salvaged.
+func splitTopLevelChunks(src []byte) []srcChunk {
+	lines := bytes.SplitAfter(src, []byte("\n"))
+	var chunks []srcChunk
+	var cur bytes.Buffer
+	curStart := 1
+	inBlock := false
+	depth := 0
+	flush := func(endLine int, block bool) {
+		if cur.Len() == 0 {
+			return
+		}
+		chunks = append(chunks, srcChunk{
+			text:      append([]byte(nil), cur.Bytes()...),
+			block:     block,
+			startLine: curStart,
+			endLine:   endLine,
+		})
+		cur.Reset()
+		curStart = endLine + 1
+	}
+	for i, line := range lines {
+		lineNo := i + 1
+		if !inBlock && chunkStartRe.Match(line) {
+			flush(lineNo-1, false)
+			inBlock = true
+			depth = 0
+		}
+		cur.Write(line)
+		if inBlock {
+			depth += bytes.Count(line, []byte("{")) - bytes.Count(line, []byte("}"))
+			if depth <= 0 && bytes.Contains(line, []byte("}")) {
+				flush(lineNo, true)
+				inBlock = false
+			}
+		}
+	}
+	flush(len(lines), inBlock)
+	return chunks
+}
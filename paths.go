@@ -0,0 +1,90 @@
+package main
+
+import (
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// longPath makes a path safe for Windows' historic 260-character limit
+// by applying the extended-length \\?\ prefix (\\?\UNC\ for UNC paths)
+// once a path gets close to the limit. On other platforms, and for
+// short paths, it returns the input unchanged.
+func longPath(path string) string {
+	if runtime.GOOS != "windows" || len(path) < 248 {
+		return path
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return path
+	}
+	switch {
+	case strings.HasPrefix(abs, `\\?\`):
+		return abs
+	case strings.HasPrefix(abs, `\\`):
+		return `\\?\UNC\` + abs[2:]
+	default:
+		return `\\?\` + abs
+	}
+}
+
+// repoRoot memoizes the git top level for repo-relative path display.
+var repoRoot struct {
+	once sync.Once
+	path string
+}
+
+func gitTopLevel() string {
+	repoRoot.once.Do(func() {
+		out, err := exec.Command("git", "rev-parse", "--show-toplevel").Output()
+		if err == nil {
+			repoRoot.path = strings.TrimSpace(string(out))
+		}
+	})
+	return repoRoot.path
+}
+
+// repoRelative renders a path relative to the repository root, the
+// form hosting-service review APIs expect regardless of where barry
+// was invoked from.
+func repoRelative(path string) string {
+	root := gitTopLevel()
+	if root == "" {
+		return filepath.ToSlash(path)
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return filepath.ToSlash(path)
+	}
+	rel, err := filepath.Rel(root, abs)
+	if err != nil {
+		return filepath.ToSlash(path)
+	}
+	return filepath.ToSlash(rel)
+}
+
+// displayPath renders a path the way the configuration asks for it -
+// relative to the working directory (the default), relative to the
+// repository root, or absolute - with path_prefix prepended for
+// container environments where mount paths differ from host paths. It
+// is applied consistently to file lists, diffs and report output.
+func displayPath(path string) string {
+	out := path
+	switch cfg.PathDisplay {
+	case "absolute":
+		if abs, err := filepath.Abs(path); err == nil {
+			out = abs
+		}
+	case "repo":
+		if root := gitTopLevel(); root != "" {
+			if abs, err := filepath.Abs(path); err == nil {
+				if rel, err := filepath.Rel(root, abs); err == nil {
+					out = rel
+				}
+			}
+		}
+	}
+	return cfg.PathPrefix + out
+}
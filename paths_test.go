@@ -0,0 +1,45 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestFileKindCaseInsensitive(t *testing.T) {
+	cfg = defaultConfig()
+	cases := map[string]string{
+		"main.tf":           "tf",
+		"MAIN.TF":           "tf",
+		"Prod.TfVars":       "tfvars",
+		"prod.auto.tfvars":  "tfvars",
+		"terraform.tfvars":  "tfvars",
+		"suite.tftest.hcl":  "tf",
+		"Suite.TFTEST.HCL":  "tf",
+		"README.md":         "",
+		"terragrunt.hcl":    "",
+		"nested/dir/a.tf":   "tf",
+		`nested\dir\a.tf`:   "tf",
+		`C:\work\infra.TF`:  "tf",
+		"archive.tf.backup": "",
+	}
+	for path, want := range cases {
+		if got := fileKind(path); got != want {
+			t.Errorf("fileKind(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
+
+func TestFormatSourceCRLFRoundTrip(t *testing.T) {
+	cfg = defaultConfig()
+	src := []byte("b = 2\r\na = 1\r\n")
+	out, err := formatSource("terraform.tfvars", src)
+	if err != nil {
+		t.Fatalf("formatSource: %s", err)
+	}
+	if !bytes.Contains(out, []byte("\r\n")) {
+		t.Fatalf("CRLF input came back with LF endings:\n%q", out)
+	}
+	if bytes.Contains(bytes.ReplaceAll(out, []byte("\r\n"), nil), []byte("\n")) {
+		t.Fatalf("output mixes line endings:\n%q", out)
+	}
+}
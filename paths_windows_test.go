@@ -0,0 +1,34 @@
+//go:build windows
+
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLongPathPrefixes(t *testing.T) {
+	long := `C:\work\` + strings.Repeat("deeply-nested-directory/", 12) + "main.tf"
+	got := longPath(long)
+	if !strings.HasPrefix(got, `\\?\`) {
+		t.Errorf("longPath(%q) = %q, want extended-length prefix", long, got)
+	}
+
+	unc := `\\fileserver\share\` + strings.Repeat("deeply-nested-directory/", 12) + "main.tf"
+	got = longPath(unc)
+	if !strings.HasPrefix(got, `\\?\UNC\fileserver\share`) {
+		t.Errorf("longPath(%q) = %q, want \\\\?\\UNC prefix", unc, got)
+	}
+
+	if short := `C:\work\main.tf`; longPath(short) != short {
+		t.Errorf("longPath(%q) rewrote a short path", short)
+	}
+}
+
+func TestFileOwnersBackslashPaths(t *testing.T) {
+	rules := []codeownersRule{{pattern: "envs/", owners: []string{"@platform"}}}
+	owners := fileOwners(rules, `envs\prod\main.tf`)
+	if len(owners) != 1 || owners[0] != "@platform" {
+		t.Errorf("fileOwners with backslash path = %v, want [@platform]", owners)
+	}
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// The exec+JSON plugin protocol: barry runs every barry-* executable in
+// plugin_dir once per file, writes a pluginRequest to its stdin and
+// reads a pluginResponse from its stdout. Plugins can report
+// diagnostics, rewrite the file content, or both.
+type pluginRequest struct {
+	Version int    `json:"version"`
+	Path    string `json:"path"`
+	Source  string `json:"source"`
+	// Tokens carries the lexed token stream; it is populated for WASM
+	// plugins, which have no HCL parser of their own.
+	Tokens []pluginToken `json:"tokens,omitempty"`
+}
+
+type pluginResponse struct {
+	Diagnostics []Diagnostic `json:"diagnostics"`
+	// Source carries the rewritten file content, or "" when the plugin
+	// leaves it unchanged. Edits is the surgical alternative: byte-offset
+	// replacements applied to the input. A response may use one or the
+	// other, not both.
+	Source string     `json:"source"`
+	Edits  []TextEdit `json:"edits,omitempty"`
+}
+
+// applyPluginResponse folds one plugin's response into the running
+// source and diagnostic list.
+func applyPluginResponse(plugin, path string, src []byte, resp pluginResponse, diags []Diagnostic) ([]byte, []Diagnostic, error) {
+	for _, d := range resp.Diagnostics {
+		if d.File == "" {
+			d.File = path
+		}
+		if d.Rule == "" {
+			d.Rule = plugin
+		}
+		diags = append(diags, d)
+	}
+	if resp.Source != "" && len(resp.Edits) > 0 {
+		return src, diags, fmt.Errorf("plugin %s: response has both source and edits", plugin)
+	}
+	if len(resp.Edits) > 0 {
+		edited, err := applyTextEdits(src, resp.Edits)
+		if err != nil {
+			return src, diags, fmt.Errorf("plugin %s: %s", plugin, err)
+		}
+		return edited, diags, nil
+	}
+	if resp.Source != "" {
+		return []byte(resp.Source), diags, nil
+	}
+	return src, diags, nil
+}
+
+// pluginProtocolVersion is bumped when the request/response shape
+// changes incompatibly.
+const pluginProtocolVersion = 1
+
+// pluginBinaries lists the executable barry-* files in the configured
+// plugin directory, in stable order. The name prefix keeps barry from
+// executing unrelated files that happen to live there.
+func pluginBinaries() ([]string, error) {
+	if cfg.PluginDir == "" {
+		return nil, nil
+	}
+	entries, err := os.ReadDir(cfg.PluginDir)
+	if err != nil {
+		return nil, err
+	}
+	var bins []string
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasPrefix(entry.Name(), "barry-") {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil || info.Mode()&0111 == 0 {
+			continue
+		}
+		bins = append(bins, filepath.Join(cfg.PluginDir, entry.Name()))
+	}
+	sort.Strings(bins)
+	return bins, nil
+}
+
+// runPlugins pipes a file through every configured plugin in turn,
+// feeding each one the previous plugin's output, and gathers their
+// diagnostics.
+func runPlugins(path string, src []byte) ([]byte, []Diagnostic, error) {
+	bins, err := pluginBinaries()
+	if err != nil {
+		return src, nil, err
+	}
+	var diags []Diagnostic
+	for _, bin := range bins {
+		req, err := json.Marshal(pluginRequest{
+			Version: pluginProtocolVersion,
+			Path:    path,
+			Source:  string(src),
+		})
+		if err != nil {
+			return src, diags, err
+		}
+		cmd := exec.Command(bin)
+		cmd.Stdin = bytes.NewReader(req)
+		var stdout, stderr bytes.Buffer
+		cmd.Stdout = &stdout
+		cmd.Stderr = &stderr
+		if err := cmd.Run(); err != nil {
+			return src, diags, fmt.Errorf("plugin %s: %s (%s)", filepath.Base(bin), err, strings.TrimSpace(stderr.String()))
+		}
+		var resp pluginResponse
+		if err := json.Unmarshal(stdout.Bytes(), &resp); err != nil {
+			return src, diags, fmt.Errorf("plugin %s: invalid response: %s", filepath.Base(bin), err)
+		}
+		src, diags, err = applyPluginResponse(filepath.Base(bin), path, src, resp, diags)
+		if err != nil {
+			return src, diags, err
+		}
+	}
+	src, wasmDiags, err := runWasmPlugins(path, src)
+	diags = append(diags, wasmDiags...)
+	return src, diags, err
+}
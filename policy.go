@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/open-policy-agent/opa/rego"
+)
+
+// Rego policies evaluate against a JSON representation of the parsed
+// configuration: the block hierarchy with attribute expressions as
+// source text. Policies live in policy_dir as *.rego files and deny
+// findings through the conftest-style data.barry.deny rule, as either
+// plain message strings or objects with msg and optional line keys.
+
+// policyFiles lists the *.rego policies in the configured directory.
+func policyFiles() ([]string, error) {
+	if cfg.PolicyDir == "" {
+		return nil, nil
+	}
+	entries, err := os.ReadDir(cfg.PolicyDir)
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".rego") {
+			files = append(files, filepath.Join(cfg.PolicyDir, entry.Name()))
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// policyBlock renders one block for policy input.
+func policyBlock(src []byte, block *hclsyntax.Block) map[string]interface{} {
+	attrs := make(map[string]interface{}, len(block.Body.Attributes))
+	for name, attr := range block.Body.Attributes {
+		rng := attr.Expr.Range()
+		attrs[name] = string(src[rng.Start.Byte:rng.End.Byte])
+	}
+	blocks := make([]interface{}, 0, len(block.Body.Blocks))
+	for _, b := range block.Body.Blocks {
+		blocks = append(blocks, policyBlock(src, b))
+	}
+	return map[string]interface{}{
+		"type":       block.Type,
+		"labels":     block.Labels,
+		"attributes": attrs,
+		"blocks":     blocks,
+		"line":       block.TypeRange.Start.Line,
+	}
+}
+
+// runPolicyChecks evaluates every configured Rego policy against the
+// file's structure and converts denials into diagnostics.
+func runPolicyChecks(path string, src []byte, body *hclsyntax.Body) ([]Diagnostic, error) {
+	files, err := policyFiles()
+	if err != nil || len(files) == 0 {
+		return nil, err
+	}
+	blocks := make([]interface{}, 0, len(body.Blocks))
+	for _, block := range body.Blocks {
+		blocks = append(blocks, policyBlock(src, block))
+	}
+	input := map[string]interface{}{
+		"path":   path,
+		"blocks": blocks,
+	}
+	ctx := context.Background()
+	query := rego.New(
+		rego.Query("data.barry.deny"),
+		rego.Load(files, nil),
+		rego.Input(input),
+	)
+	rs, err := query.Eval(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("policy evaluation: %s", err)
+	}
+	var diags []Diagnostic
+	for _, result := range rs {
+		for _, expr := range result.Expressions {
+			values, ok := expr.Value.([]interface{})
+			if !ok {
+				continue
+			}
+			for _, value := range values {
+				diag := Diagnostic{Rule: "policy", File: path, Line: 1, Column: 1}
+				switch v := value.(type) {
+				case string:
+					diag.Message = v
+				case map[string]interface{}:
+					if msg, ok := v["msg"].(string); ok {
+						diag.Message = msg
+					}
+					if line, ok := v["line"].(float64); ok {
+						diag.Line = int(line)
+					}
+				default:
+					continue
+				}
+				if diag.Message != "" {
+					diags = append(diags, diag)
+				}
+			}
+		}
+	}
+	return diags, nil
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// runPR implements the pr subcommand, the building block for a nightly
+// formatting bot: format the repo on a fresh branch, commit, push, and
+// open a pull or merge request whose description summarizes the
+// changes. The working tree must be clean to start.
+func runPR(args []string) int {
+	fs := flag.NewFlagSet("pr", flag.ExitOnError)
+	github := fs.Bool("github", false, "open the pull request on GitHub")
+	gitlab := fs.Bool("gitlab", false, "open the merge request on GitLab")
+	repo := fs.String("repo", "", "repository in owner/name form (GitHub)")
+	project := fs.String("project", "", "project ID or path (GitLab)")
+	branch := fs.String("branch", "", "branch to create (default barry/format-YYYYMMDD)")
+	title := fs.String("title", "Format Terraform configuration with barry", "pull request title")
+	configFile := fs.String("config", "", "path to a config file (defaults to .barry.hcl if present)")
+	fs.Parse(args)
+
+	var err error
+	cfg, err = loadConfig(*configFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "barry: %s\n", err)
+		return 1
+	}
+	if out, err := exec.Command("git", "status", "--porcelain").Output(); err != nil || strings.TrimSpace(string(out)) != "" {
+		fmt.Fprintln(os.Stderr, "barry: pr needs a clean working tree")
+		return 1
+	}
+	base, err := gitCurrentBranch()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "barry: %s\n", err)
+		return 1
+	}
+	name := *branch
+	if name == "" {
+		name = "barry/format-" + time.Now().Format("20060102")
+	}
+	if out, err := exec.Command("git", "checkout", "-b", name).CombinedOutput(); err != nil {
+		fmt.Fprintf(os.Stderr, "barry: git checkout: %s (%s)\n", err, strings.TrimSpace(string(out)))
+		return 1
+	}
+
+	targets := fs.Args()
+	if len(targets) == 0 {
+		targets = []string{"."}
+	}
+	opts.write = true
+	opts.recursive = true
+	if code := run(targets); code == 1 {
+		return 1
+	}
+	if len(writtenFiles) == 0 {
+		fmt.Println("nothing to format")
+		exec.Command("git", "checkout", base).Run()
+		exec.Command("git", "branch", "-d", name).Run()
+		return 0
+	}
+	if err := gitCommitChanges(writtenFiles, *title); err != nil {
+		fmt.Fprintf(os.Stderr, "barry: %s\n", err)
+		return 1
+	}
+	if out, err := exec.Command("git", "push", "-u", "origin", name).CombinedOutput(); err != nil {
+		fmt.Fprintf(os.Stderr, "barry: git push: %s (%s)\n", err, strings.TrimSpace(string(out)))
+		return 1
+	}
+
+	body := prBody(writtenFiles)
+	switch {
+	case *github:
+		err = openGitHubPR(*repo, *title, body, name, base)
+	case *gitlab:
+		err = openGitLabMR(*project, *title, body, name, base)
+	default:
+		fmt.Printf("branch %s pushed; open the pull request manually or pass -github/-gitlab\n", name)
+		return 0
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "barry: %s\n", err)
+		return 1
+	}
+	fmt.Printf("opened pull request for %s\n", name)
+	return 0
+}
+
+func gitCurrentBranch() (string, error) {
+	out, err := exec.Command("git", "rev-parse", "--abbrev-ref", "HEAD").Output()
+	if err != nil {
+		return "", fmt.Errorf("git: %w", err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// prBody summarizes the change set for the request description.
+func prBody(files []string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "barry reformatted %d file(s):\n\n", len(files))
+	for i, file := range files {
+		if i == 50 {
+			fmt.Fprintf(&b, "- ... and %d more\n", len(files)-50)
+			break
+		}
+		fmt.Fprintf(&b, "- `%s`\n", file)
+	}
+	return b.String()
+}
+
+func openGitHubPR(repo, title, body, head, base string) error {
+	provider, err := newGitHubProvider(repo, 1) // the PR number is not used here
+	if err != nil {
+		return err
+	}
+	api := fmt.Sprintf("%s/repos/%s/pulls", provider.apiURL, provider.repo)
+	headers := map[string]string{
+		"Authorization": "Bearer " + provider.token,
+		"Accept":        "application/vnd.github+json",
+	}
+	payload := map[string]string{"title": title, "body": body, "head": head, "base": base}
+	if err := vcsRequest("POST", api, headers, payload, nil); err != nil {
+		return fmt.Errorf("github: %s", err)
+	}
+	return nil
+}
+
+func openGitLabMR(project, title, body, source, target string) error {
+	provider, err := newGitLabProvider(project, 1) // the MR number is not used here
+	if err != nil {
+		return err
+	}
+	api := fmt.Sprintf("%s/api/v4/projects/%s/merge_requests", provider.baseURL, url.PathEscape(provider.project))
+	headers := map[string]string{"PRIVATE-TOKEN": provider.token}
+	payload := map[string]string{
+		"title":         title,
+		"description":   body,
+		"source_branch": source,
+		"target_branch": target,
+	}
+	if err := vcsRequest("POST", api, headers, payload, nil); err != nil {
+		return fmt.Errorf("gitlab: %s", err)
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package main
+
+// A fileResult records the outcome for one processed file, with the
+// before/after content kept around so report renderers can show diffs.
+type fileResult struct {
+	Path    string
+	Changed bool
+	Err     string
+	Before  []byte
+	After   []byte
+}
+
+// report collects results across a run when a non-text output format was
+// requested; it stays nil in plain text mode, where results are printed
+// as they happen.
+var report *runReport
+
+type runReport struct {
+	results []fileResult
+}
+
+func (r *runReport) add(res fileResult) {
+	r.results = append(r.results, res)
+}
+
+func (r *runReport) changedCount() int {
+	n := 0
+	for _, res := range r.results {
+		if res.Changed {
+			n++
+		}
+	}
+	return n
+}
+
+func (r *runReport) errorCount() int {
+	n := 0
+	for _, res := range r.results {
+		if res.Err != "" {
+			n++
+		}
+	}
+	return n
+}
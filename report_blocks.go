@@ -0,0 +1,67 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+// changedBlocks returns the addresses of the top-level blocks whose
+// lines a change set touches, so reviewers of large files know where to
+// look. Addresses use the usual resource.aws_iam_role.deploy form,
+// falling back to the block type and labels for unaddressable blocks.
+func changedBlocks(path string, before, after []byte) []string {
+	f, diags := hclsyntax.ParseConfig(before, path, hcl.InitialPos)
+	if diags.HasErrors() {
+		return nil
+	}
+	body, ok := f.Body.(*hclsyntax.Body)
+	if !ok {
+		return nil
+	}
+	type span struct {
+		addr       string
+		start, end int
+	}
+	var spans []span
+	for _, block := range body.Blocks {
+		addr := blockAddress(block.Type, block.Labels)
+		if addr == "" {
+			addr = strings.Join(append([]string{block.Type}, block.Labels...), ".")
+		}
+		if block.Type == "resource" && len(block.Labels) == 2 {
+			addr = "resource." + block.Labels[0] + "." + block.Labels[1]
+		}
+		spans = append(spans, span{
+			addr:  addr,
+			start: block.DefRange().Start.Line,
+			end:   block.Body.EndRange.End.Line,
+		})
+	}
+	seen := make(map[string]bool)
+	var addrs []string
+	for _, h := range diffHunks(before, after) {
+		hunkEnd := h.OldStart + h.OldLines
+		if h.OldLines > 0 {
+			hunkEnd = h.OldStart + h.OldLines - 1
+		}
+		for _, s := range spans {
+			if h.OldStart > s.end || hunkEnd < s.start || seen[s.addr] {
+				continue
+			}
+			seen[s.addr] = true
+			addrs = append(addrs, s.addr)
+		}
+	}
+	return addrs
+}
+
+// printChangedBlocks writes the addresses indented beneath a check-mode
+// path line.
+func printChangedBlocks(before, after []byte, path string) {
+	for _, addr := range changedBlocks(path, before, after) {
+		fmt.Printf("  ~ %s\n", addr)
+	}
+}
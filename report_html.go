@@ -0,0 +1,124 @@
+package main
+
+import (
+	"html/template"
+	"io"
+	"sort"
+	"strings"
+)
+
+// renderHTMLReport writes a standalone HTML report of a check run:
+// summary bar, per-file status and expandable highlighted diffs, sized
+// for publishing as a CI artifact.
+func renderHTMLReport(w io.Writer, rep *runReport) error {
+	type diffLine struct {
+		Class string
+		Text  string
+	}
+	type htmlFile struct {
+		Path  string
+		Err   string
+		Lines []diffLine
+	}
+	var files []htmlFile
+	for _, res := range rep.results {
+		if !res.Changed && res.Err == "" {
+			continue
+		}
+		hf := htmlFile{Path: displayPath(res.Path), Err: res.Err}
+		if res.Changed {
+			text, err := diffBytes(res.Path, res.Before, res.After)
+			if err == nil {
+				for _, line := range strings.Split(strings.TrimRight(text, "\n"), "\n") {
+					class := "ctx"
+					switch {
+					case strings.HasPrefix(line, "+"):
+						class = "add"
+					case strings.HasPrefix(line, "-"):
+						class = "del"
+					case strings.HasPrefix(line, "@@"):
+						class = "hunk"
+					}
+					hf.Lines = append(hf.Lines, diffLine{Class: class, Text: line})
+				}
+			}
+		}
+		files = append(files, hf)
+	}
+	type ownerGroup struct {
+		Owner string
+		Files []string
+	}
+	var owners []ownerGroup
+	if grouped := ownersBreakdown(rep); len(grouped) > 0 {
+		names := make([]string, 0, len(grouped))
+		for owner := range grouped {
+			names = append(names, owner)
+		}
+		sort.Strings(names)
+		for _, owner := range names {
+			owners = append(owners, ownerGroup{Owner: owner, Files: grouped[owner]})
+		}
+	}
+	data := struct {
+		Total    int
+		Changed  int
+		Errors   int
+		CleanPct int
+		Files    []htmlFile
+		Owners   []ownerGroup
+	}{
+		Total:   len(rep.results),
+		Changed: rep.changedCount(),
+		Errors:  rep.errorCount(),
+		Files:   files,
+		Owners:  owners,
+	}
+	if data.Total > 0 {
+		data.CleanPct = 100 * (data.Total - data.Changed - data.Errors) / data.Total
+	}
+	return htmlReportTemplate.Execute(w, data)
+}
+
+var htmlReportTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>barry report</title>
+<style>
+body { font-family: sans-serif; margin: 2em; color: #222; }
+.bar { background: #e5534b; border-radius: 4px; overflow: hidden; height: 14px; width: 320px; }
+.bar > div { background: #57ab5a; height: 100%; }
+pre { background: #f6f8fa; padding: 0.8em; overflow-x: auto; }
+.add { color: #1a7f37; }
+.del { color: #cf222e; }
+.hunk { color: #6639ba; }
+.ctx { color: #57606a; }
+summary { cursor: pointer; font-family: monospace; margin: 0.4em 0; }
+.err { color: #cf222e; font-family: monospace; }
+</style>
+</head>
+<body>
+<h1>barry report</h1>
+<p>{{.Total}} file(s) checked, {{.Changed}} unformatted, {{.Errors}} error(s)</p>
+<div class="bar"><div style="width: {{.CleanPct}}%"></div></div>
+{{if .Owners}}
+<h2>By owner</h2>
+<ul>
+{{range .Owners}}<li><strong>{{.Owner}}</strong>: {{range .Files}}<code>{{.}}</code> {{end}}</li>
+{{end}}</ul>
+{{end}}
+{{range .Files}}
+{{if .Err}}
+<p class="err">{{.Path}}: {{.Err}}</p>
+{{else}}
+<details>
+<summary>{{.Path}}</summary>
+<pre>{{range .Lines}}<span class="{{.Class}}">{{.Text}}</span>
+{{end}}</pre>
+</details>
+{{end}}
+{{end}}
+</body>
+</html>
+`))
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+)
+
+// renderMarkdownReport writes a compact report sized for pasting into a
+// PR description or posting via a CI bot comment: a summary line, a
+// table of offending files and collapsible diff sections.
+func renderMarkdownReport(w io.Writer, rep *runReport) error {
+	changed, errors := rep.changedCount(), rep.errorCount()
+	fmt.Fprintf(w, "### barry check\n\n%d file(s) checked, %d unformatted, %d error(s)\n\n",
+		len(rep.results), changed, errors)
+	if changed == 0 && errors == 0 {
+		fmt.Fprintln(w, "All files are formatted.")
+		return nil
+	}
+	fmt.Fprintln(w, "| File | Status |")
+	fmt.Fprintln(w, "| --- | --- |")
+	for _, res := range rep.results {
+		switch {
+		case res.Err != "":
+			fmt.Fprintf(w, "| `%s` | error: %s |\n", displayPath(res.Path), res.Err)
+		case res.Changed:
+			fmt.Fprintf(w, "| `%s` | unformatted |\n", displayPath(res.Path))
+		}
+	}
+	fmt.Fprintln(w)
+	if grouped := ownersBreakdown(rep); len(grouped) > 0 {
+		fmt.Fprintln(w, "#### By owner")
+		fmt.Fprintln(w)
+		owners := make([]string, 0, len(grouped))
+		for owner := range grouped {
+			owners = append(owners, owner)
+		}
+		sort.Strings(owners)
+		for _, owner := range owners {
+			fmt.Fprintf(w, "- %s: %d file(s)\n", owner, len(grouped[owner]))
+		}
+		fmt.Fprintln(w)
+	}
+	for _, res := range rep.results {
+		if !res.Changed {
+			continue
+		}
+		text, err := diffBytes(res.Path, res.Before, res.After)
+		if err != nil {
+			continue
+		}
+		fmt.Fprintf(w, "<details><summary><code>%s</code></summary>\n\n```diff\n%s```\n</details>\n\n", displayPath(res.Path), text)
+	}
+	return nil
+}
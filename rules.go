@@ -0,0 +1,168 @@
+package main
+
+// A Rule is an optional transform with a stable identifier that can be
+// enabled or disabled via the rules map in configuration.
+type Rule struct {
+	ID          string
+	Description string
+	// Default is the rule's state when the configuration does not
+	// mention it. Opinionated rewrites default to off.
+	Default bool
+}
+
+// Rules is the registry of togglable rules, in the order they were
+// introduced.
+var Rules = []*Rule{
+	{
+		ID:          "sort_depends_on",
+		Description: "alphabetize depends_on and lifecycle.ignore_changes lists",
+	},
+	{
+		ID:          "unquote_references",
+		Description: "rewrite legacy quoted references in depends_on and providers to bare references",
+	},
+	{
+		ID:          "legacy_splat",
+		Description: "rewrite a.*.id attribute splats to a[*].id",
+	},
+	{
+		ID:          "legacy_element",
+		Description: "rewrite element(list, i) calls to index syntax where safe",
+	},
+	{
+		ID:          "legacy_collections",
+		Description: "rewrite list(...) and map(...) calls to literal forms",
+	},
+	{
+		ID:          "redundant_parens",
+		Description: "strip unnecessary parentheses wrapping single-line expressions",
+	},
+	{
+		ID:          "simplify_booleans",
+		Description: "rewrite x == true, x == false and cond ? true : false to their minimal forms",
+	},
+	{
+		ID:          "unquote_literals",
+		Description: "rewrite quoted numbers and booleans in well-known arguments to bare literals",
+	},
+	{
+		ID:          "sort_type_keys",
+		Description: "alphabetize the attributes of object({...}) type constraints",
+	},
+	{
+		ID:          "format_json",
+		Description: "pretty-print JSON heredoc bodies with stable key order",
+	},
+	{
+		ID:          "format_yaml",
+		Description: "normalize YAML heredoc bodies via a decode/encode round-trip",
+	},
+	{
+		ID:          "jsonencode_heredocs",
+		Description: "convert static JSON heredocs to jsonencode() expressions",
+	},
+	{
+		ID:          "reflow_comments",
+		Description: "re-wrap long # comment paragraphs at the configured width",
+	},
+	{
+		ID:          "comment_spacing",
+		Description: "normalize spacing after # markers and before trailing comments",
+	},
+	{
+		ID:          "collapse_banners",
+		Description: "reduce ##-style comment banners to a single # (with comment_spacing)",
+	},
+	{
+		ID:          "collapse_short_collections",
+		Description: "collapse single-element lists and objects onto one line when they fit",
+	},
+	{
+		ID:          "attribute_ordering",
+		Description: "group and sort block attributes and nested blocks",
+		Default:     true,
+	},
+	{
+		ID:          "comment_conversion",
+		Description: "rewrite // comments to the idiomatic # form",
+		Default:     true,
+	},
+	{
+		ID:          "locals_sections",
+		Description: "sort locals within comment-headed sections instead of across the whole block",
+		Default:     true,
+	},
+	{
+		ID:          "sort_nested_block_labels",
+		Description: "order same-type nested blocks by their labels, with a stable tiebreak",
+	},
+	{
+		ID:          "partial_format",
+		Description: "format the valid top-level blocks of files whose parse fails elsewhere",
+		Default:     true,
+	},
+	{
+		ID:          "normalize_labels",
+		Description: "rewrite block labels with canonical quoting (discards comments between labels)",
+		Default:     true,
+	},
+	{
+		ID:          "expand_one_line_blocks",
+		Description: "expand inline blocks like lifecycle { create_before_destroy = true } to multi-line form",
+		Default:     true,
+	},
+}
+
+// legacyRuleIDs are the rules enabled together by the -fix-legacy flag.
+var legacyRuleIDs = []string{
+	"unquote_references",
+	"legacy_splat",
+	"legacy_element",
+	"legacy_collections",
+}
+
+// knownRuleIDs collects every registered formatting, lint and module
+// rule ID, the universe the config's rules map may name.
+func knownRuleIDs() map[string]bool {
+	known := make(map[string]bool)
+	for _, rule := range Rules {
+		known[rule.ID] = true
+	}
+	for _, rule := range LintRules {
+		known[rule.ID] = true
+	}
+	for _, rule := range ModuleLintRules {
+		known[rule.ID] = true
+	}
+	return known
+}
+
+// ruleEnabled reports whether the rule with the given ID is active under
+// the current configuration.
+func ruleEnabled(id string) bool {
+	if activeFileType != nil {
+		if v, ok := activeFileType.Rules[id]; ok {
+			return v
+		}
+	}
+	if v, ok := cfg.Rules[id]; ok {
+		return v
+	}
+	if (opts.fixLegacy || opts.upgradeLegacy) && indexOf(legacyRuleIDs, id) >= 0 {
+		return true
+	}
+	if opts.upgradeLegacy && id == "unquote_literals" {
+		return true
+	}
+	if cfg.StyleVersion != "" {
+		if v, mentioned := styleDefaultFor(id, cfg.StyleVersion); mentioned {
+			return v
+		}
+	}
+	for _, rule := range Rules {
+		if rule.ID == id {
+			return rule.Default
+		}
+	}
+	return false
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+// providerSchemas is the slice of `terraform providers schema -json`
+// output barry cares about: for each resource and data source type,
+// which attributes are required, optional or computed-only. With a
+// schema loaded, attribute ordering becomes semantically meaningful
+// instead of flat-alphabetical.
+type providerSchemas struct {
+	resources   map[string]map[string]schemaAttr
+	dataSources map[string]map[string]schemaAttr
+}
+
+type schemaAttr struct {
+	Required bool `json:"required"`
+	Optional bool `json:"optional"`
+	Computed bool `json:"computed"`
+}
+
+// activeSchemas is set when -schema-file is passed.
+var activeSchemas *providerSchemas
+
+// loadProviderSchemas parses a terraform providers schema -json dump.
+func loadProviderSchemas(path string) (*providerSchemas, error) {
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var raw struct {
+		ProviderSchemas map[string]struct {
+			ResourceSchemas   map[string]schemaBlockWrapper `json:"resource_schemas"`
+			DataSourceSchemas map[string]schemaBlockWrapper `json:"data_source_schemas"`
+		} `json:"provider_schemas"`
+	}
+	if err := json.Unmarshal(src, &raw); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	out := &providerSchemas{
+		resources:   make(map[string]map[string]schemaAttr),
+		dataSources: make(map[string]map[string]schemaAttr),
+	}
+	for _, provider := range raw.ProviderSchemas {
+		for name, wrapper := range provider.ResourceSchemas {
+			out.resources[name] = wrapper.Block.Attributes
+		}
+		for name, wrapper := range provider.DataSourceSchemas {
+			out.dataSources[name] = wrapper.Block.Attributes
+		}
+	}
+	return out, nil
+}
+
+type schemaBlockWrapper struct {
+	Block struct {
+		Attributes map[string]schemaAttr `json:"attributes"`
+	} `json:"block"`
+}
+
+// lookup finds the attribute schema for a block, keyed by its resource
+// or data source type.
+func (s *providerSchemas) lookup(blockType string, labels []string) map[string]schemaAttr {
+	if s == nil || len(labels) == 0 {
+		return nil
+	}
+	switch blockType {
+	case "resource", "ephemeral":
+		return s.resources[labels[0]]
+	case "data":
+		return s.dataSources[labels[0]]
+	}
+	return nil
+}
+
+// schemaRank orders attributes required-first, optional-second, then
+// anything the schema does not know.
+func schemaRank(attrs map[string]schemaAttr, name string) int {
+	attr, ok := attrs[name]
+	switch {
+	case !ok:
+		return 2
+	case attr.Required:
+		return 0
+	default:
+		return 1
+	}
+}
+
+// schemaTypoRule flags attribute names that do not exist in the loaded
+// provider schema - likely typos Terraform would only catch at plan
+// time. It stays quiet without -schema-file, and only checks the top
+// level of each resource body; nested block schemas are not loaded.
+var schemaTypoRule = &LintRule{
+	ID:          "schema_typos",
+	Description: "resource attributes should exist in the provider schema",
+	Check: func(path string, body *hclsyntax.Body) []Diagnostic {
+		if activeSchemas == nil {
+			return nil
+		}
+		var diags []Diagnostic
+		for _, block := range body.Blocks {
+			attrs := activeSchemas.lookup(block.Type, block.Labels)
+			if attrs == nil {
+				continue
+			}
+			for name, attr := range block.Body.Attributes {
+				if isMetaArgument(name) {
+					continue
+				}
+				if _, ok := attrs[name]; !ok {
+					diags = append(diags, Diagnostic{
+						Rule:    "schema_typos",
+						File:    path,
+						Line:    attr.NameRange.Start.Line,
+						Column:  attr.NameRange.Start.Column,
+						Message: fmt.Sprintf("%q is not an attribute of %s according to the provider schema", name, block.Labels[0]),
+					})
+				}
+			}
+		}
+		return diags
+	},
+}
+
+// flagComputedOnly warns about attributes being set even though the
+// schema marks them computed-only; Terraform would reject them at plan
+// time.
+func flagComputedOnly(attrs map[string]schemaAttr, names []string, blockType string, labels []string) {
+	for _, name := range names {
+		if attr, ok := attrs[name]; ok && attr.Computed && !attr.Optional && !attr.Required {
+			fmt.Fprintf(os.Stderr, "barry: warning: %s %v: %q is computed-only according to the provider schema\n",
+				blockType, labels, name)
+		}
+	}
+}
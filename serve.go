@@ -0,0 +1,119 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+// formatMu serializes the formatting pipeline in serve mode: the
+// formatter threads per-file state through package globals, so only one
+// request may run it at a time. The -concurrency semaphore still bounds
+// how many requests queue behind it.
+var formatMu sync.Mutex
+
+// serveReady flips to false once shutdown begins, so readiness probes
+// steer traffic away while in-flight requests drain.
+var serveReady atomic.Bool
+
+// runServe implements the serve subcommand: a small HTTP formatting
+// service. POST /format takes HCL source in the request body (with the
+// logical filename in ?path= so extension rules apply) and answers with
+// the formatted source. /metrics exposes the Prometheus counters,
+// /healthz reports liveness and /readyz readiness; SIGTERM drains
+// connections before exit, so the server sits comfortably behind
+// Kubernetes probes.
+func runServe(args []string) int {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	addr := fs.String("addr", ":8765", "address to listen on")
+	configFile := fs.String("config", "", "path to a config file (defaults to .barry.hcl if present)")
+	concurrency := fs.Int("concurrency", 8, "maximum concurrent format requests")
+	drainTimeout := fs.Duration("drain-timeout", 30*time.Second, "how long to wait for in-flight requests on shutdown")
+	fs.Parse(args)
+
+	var err error
+	cfg, err = loadConfig(*configFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "barry: %s\n", err)
+		return 1
+	}
+	sem := make(chan struct{}, *concurrency)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/format", func(w http.ResponseWriter, r *http.Request) {
+		sem <- struct{}{}
+		defer func() { <-sem }()
+		handleFormat(w, r)
+	})
+	mux.HandleFunc("/metrics", handleMetrics)
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, "ok")
+	})
+	mux.HandleFunc("/readyz", func(w http.ResponseWriter, r *http.Request) {
+		if !serveReady.Load() {
+			http.Error(w, "draining", http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprintln(w, "ok")
+	})
+
+	srv := &http.Server{Addr: *addr, Handler: mux}
+	done := make(chan int, 1)
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, syscall.SIGTERM, syscall.SIGINT)
+	go func() {
+		<-sig
+		serveReady.Store(false)
+		fmt.Fprintln(os.Stderr, "barry: draining connections")
+		ctx, cancel := context.WithTimeout(context.Background(), *drainTimeout)
+		defer cancel()
+		if err := srv.Shutdown(ctx); err != nil {
+			fmt.Fprintf(os.Stderr, "barry: shutdown: %s\n", err)
+			done <- 1
+			return
+		}
+		done <- 0
+	}()
+
+	serveReady.Store(true)
+	fmt.Fprintf(os.Stderr, "barry: serving on %s\n", *addr)
+	if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		fmt.Fprintf(os.Stderr, "barry: %s\n", err)
+		return 1
+	}
+	return <-done
+}
+
+func handleFormat(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "POST required", http.StatusMethodNotAllowed)
+		return
+	}
+	src, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	path := r.URL.Query().Get("path")
+	if path == "" {
+		path = "main.tf"
+	}
+	start := time.Now()
+	formatMu.Lock()
+	out, err := formatSource(path, src)
+	formatMu.Unlock()
+	metrics.observe(time.Since(start), err == nil && string(out) != string(src), err)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+	w.Header().Set("Content-Type", "application/hcl")
+	w.Write(out)
+}
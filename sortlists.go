@@ -0,0 +1,253 @@
+package main
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+)
+
+// sortReferenceList alphabetizes the elements of a list literal whose
+// elements are all simple references (aws_instance.x, module.y.id); the
+// order of such lists is non-semantic and keeping them sorted avoids
+// merge conflicts. Lists containing anything more exotic - function
+// calls, strings, comments - are left untouched.
+func sortReferenceList(body *hclwrite.Body, name string, attr *hclwrite.Attribute) {
+	elems, multiline, ok := splitCollection(attr, hclsyntax.TokenOBrack, hclsyntax.TokenCBrack)
+	if !ok || len(elems) < 2 {
+		return
+	}
+	keys := make([]string, len(elems))
+	for i, elem := range elems {
+		for _, tok := range elem {
+			switch tok.Type {
+			case hclsyntax.TokenIdent, hclsyntax.TokenDot:
+			default:
+				return
+			}
+		}
+		keys[i] = tokensText(elem)
+	}
+	order := sortOrder(keys)
+	if orderIsIdentity(order) {
+		return
+	}
+	countRule("sort_depends_on")
+	body.SetAttributeRaw(name, joinListElements(elems, order, multiline))
+}
+
+// sortLiteralCollection sorts an attribute's literal collection value:
+// object literals by key, list literals of plain strings by element.
+// Anything else is left untouched.
+func sortLiteralCollection(body *hclwrite.Body, name string, attr *hclwrite.Attribute) {
+	toks := attr.Expr().BuildTokens(nil)
+	if len(toks) == 0 {
+		return
+	}
+	switch toks[0].Type {
+	case hclsyntax.TokenOBrace:
+		sortObjectLiteral(body, name, attr)
+	case hclsyntax.TokenOBrack:
+		sortStringList(body, name, attr)
+	}
+}
+
+// sortStringList alphabetizes the elements of a list literal whose
+// elements are all plain quoted strings (availability zones, IAM
+// actions), which keeps hand-maintained lists merge-friendly.
+func sortStringList(body *hclwrite.Body, name string, attr *hclwrite.Attribute) {
+	elems, multiline, ok := splitCollection(attr, hclsyntax.TokenOBrack, hclsyntax.TokenCBrack)
+	if !ok || len(elems) < 2 {
+		return
+	}
+	keys := make([]string, len(elems))
+	for i, elem := range elems {
+		core := elem
+		if endsWithNewline(core) {
+			core = core[:len(core)-1]
+		}
+		if len(core) != 3 || core[0].Type != hclsyntax.TokenOQuote ||
+			core[1].Type != hclsyntax.TokenQuotedLit || core[2].Type != hclsyntax.TokenCQuote {
+			return
+		}
+		keys[i] = string(core[1].Bytes)
+	}
+	order := sortOrder(keys)
+	if orderIsIdentity(order) {
+		return
+	}
+	countRule("sort_directive")
+	body.SetAttributeRaw(name, joinListElements(elems, order, multiline))
+}
+
+// sortObjectLiteral sorts the key = value items of an object literal
+// attribute alphabetically by key. Items whose key cannot be identified
+// (spread expressions, stray comments) make the whole literal ineligible.
+func sortObjectLiteral(body *hclwrite.Body, name string, attr *hclwrite.Attribute) {
+	elems, multiline, ok := splitCollection(attr, hclsyntax.TokenOBrace, hclsyntax.TokenCBrace)
+	if !ok || len(elems) < 2 {
+		return
+	}
+	keys := make([]string, len(elems))
+	for i, elem := range elems {
+		key, ok := objectElementKey(elem)
+		if !ok {
+			return
+		}
+		keys[i] = key
+	}
+	order := sortOrder(keys)
+	if orderIsIdentity(order) {
+		return
+	}
+	countRule("sort_directive")
+	body.SetAttributeRaw(name, joinObjectElements(elems, order, multiline))
+}
+
+// objectElementKey extracts the key text of a key = value (or key: value)
+// item.
+func objectElementKey(elem hclwrite.Tokens) (string, bool) {
+	for i, tok := range elem {
+		switch tok.Type {
+		case hclsyntax.TokenEqual, hclsyntax.TokenColon:
+			return tokensText(elem[:i]), i > 0
+		}
+	}
+	return "", false
+}
+
+// sortOrder returns element indices ordered by their sort keys, stable
+// with respect to the original order.
+func sortOrder(keys []string) []int {
+	order := make([]int, len(keys))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return keys[order[i]] < keys[order[j]]
+	})
+	return order
+}
+
+// orderIsIdentity reports whether sorting left every element in place.
+func orderIsIdentity(order []int) bool {
+	for i, idx := range order {
+		if i != idx {
+			return false
+		}
+	}
+	return true
+}
+
+// splitCollection breaks a list or object literal expression into
+// per-element token runs, reporting whether the literal spanned multiple
+// lines. ok is false when the expression is not delimited by open/close.
+// A trailing line comment ends an element just like a newline does, and
+// stays attached to its element.
+func splitCollection(attr *hclwrite.Attribute, open, close hclsyntax.TokenType) (elems []hclwrite.Tokens, multiline, ok bool) {
+	toks := attr.Expr().BuildTokens(nil)
+	if len(toks) < 2 || toks[0].Type != open || toks[len(toks)-1].Type != close {
+		return nil, false, false
+	}
+	depth := 0
+	var cur hclwrite.Tokens
+	flush := func() {
+		if len(cur) > 0 {
+			elems = append(elems, cur)
+			cur = nil
+		}
+	}
+	for _, tok := range toks[1 : len(toks)-1] {
+		switch tok.Type {
+		case hclsyntax.TokenOBrack, hclsyntax.TokenOBrace, hclsyntax.TokenOParen:
+			depth++
+		case hclsyntax.TokenCBrack, hclsyntax.TokenCBrace, hclsyntax.TokenCParen:
+			depth--
+		}
+		if depth == 0 {
+			switch {
+			case tok.Type == hclsyntax.TokenComma:
+				flush()
+				continue
+			case tok.Type == hclsyntax.TokenNewline:
+				multiline = true
+				flush()
+				continue
+			case tok.Type == hclsyntax.TokenComment && strings.HasSuffix(string(tok.Bytes), "\n"):
+				// A line comment swallows its newline; it terminates the
+				// element but travels with it.
+				multiline = true
+				cur = append(cur, tok)
+				flush()
+				continue
+			}
+		}
+		cur = append(cur, tok)
+	}
+	flush()
+	return elems, multiline, true
+}
+
+// endsWithNewline reports whether a token run already ends the line, as
+// an element with a trailing line comment does.
+func endsWithNewline(toks hclwrite.Tokens) bool {
+	if len(toks) == 0 {
+		return false
+	}
+	last := toks[len(toks)-1]
+	return last.Type == hclsyntax.TokenComment && strings.HasSuffix(string(last.Bytes), "\n")
+}
+
+// joinListElements rebuilds a list literal from element token runs in the
+// given order, keeping the original single-line or multi-line shape.
+func joinListElements(elems []hclwrite.Tokens, order []int, multiline bool) hclwrite.Tokens {
+	out := hclwrite.Tokens{{Type: hclsyntax.TokenOBrack, Bytes: []byte("[")}}
+	for i, idx := range order {
+		if multiline {
+			if i == 0 || !endsWithNewline(elems[order[i-1]]) {
+				out = append(out, &hclwrite.Token{Type: hclsyntax.TokenNewline, Bytes: []byte("\n")})
+			}
+		}
+		elem := elems[idx]
+		trailing := endsWithNewline(elem)
+		if trailing {
+			out = append(out, elem[:len(elem)-1]...)
+		} else {
+			out = append(out, elem...)
+		}
+		if multiline || i < len(order)-1 {
+			out = append(out, &hclwrite.Token{Type: hclsyntax.TokenComma, Bytes: []byte(",")})
+		}
+		if trailing {
+			out = append(out, elem[len(elem)-1])
+		}
+	}
+	if multiline && !endsWithNewline(elems[order[len(order)-1]]) {
+		out = append(out, &hclwrite.Token{Type: hclsyntax.TokenNewline, Bytes: []byte("\n")})
+	}
+	out = append(out, &hclwrite.Token{Type: hclsyntax.TokenCBrack, Bytes: []byte("]")})
+	return out
+}
+
+// joinObjectElements rebuilds an object literal from element token runs
+// in the given order. Multi-line objects separate items with newlines;
+// single-line objects with commas.
+func joinObjectElements(elems []hclwrite.Tokens, order []int, multiline bool) hclwrite.Tokens {
+	out := hclwrite.Tokens{{Type: hclsyntax.TokenOBrace, Bytes: []byte("{")}}
+	for i, idx := range order {
+		if multiline {
+			if i == 0 || !endsWithNewline(elems[order[i-1]]) {
+				out = append(out, &hclwrite.Token{Type: hclsyntax.TokenNewline, Bytes: []byte("\n")})
+			}
+		} else if i > 0 {
+			out = append(out, &hclwrite.Token{Type: hclsyntax.TokenComma, Bytes: []byte(",")})
+		}
+		out = append(out, elems[idx]...)
+	}
+	if multiline && !endsWithNewline(elems[order[len(order)-1]]) {
+		out = append(out, &hclwrite.Token{Type: hclsyntax.TokenNewline, Bytes: []byte("\n")})
+	}
+	out = append(out, &hclwrite.Token{Type: hclsyntax.TokenCBrace, Bytes: []byte("}")})
+	return out
+}
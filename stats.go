@@ -0,0 +1,86 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+)
+
+// ruleStats counts how many times each rule actually changed something
+// during this run, so teams can see which opinionated rules cause the
+// most churn before enabling them repo-wide. The counters also feed the
+// serve/watch metrics.
+var ruleStats = struct {
+	mu     sync.Mutex
+	counts map[string]int
+}{counts: make(map[string]int)}
+
+// countRule records one modification made by the rule.
+func countRule(id string) {
+	ruleStats.mu.Lock()
+	ruleStats.counts[id]++
+	ruleStats.mu.Unlock()
+	metrics.ruleTriggered(id)
+}
+
+// rulesSnapshot copies the current counts so a caller can ask which
+// rules fired across one file.
+func rulesSnapshot() map[string]int {
+	ruleStats.mu.Lock()
+	defer ruleStats.mu.Unlock()
+	snap := make(map[string]int, len(ruleStats.counts))
+	for id, n := range ruleStats.counts {
+		snap[id] = n
+	}
+	return snap
+}
+
+// rulesChangedSince lists, sorted, the rules whose counts moved past a
+// snapshot.
+func rulesChangedSince(snap map[string]int) []string {
+	ruleStats.mu.Lock()
+	defer ruleStats.mu.Unlock()
+	var ids []string
+	for id, n := range ruleStats.counts {
+		if n > snap[id] {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// printRuleStats writes the per-rule modification counts, largest
+// first, as a human table or JSON.
+func printRuleStats(w io.Writer, jsonOut bool) {
+	ruleStats.mu.Lock()
+	counts := make(map[string]int, len(ruleStats.counts))
+	for id, n := range ruleStats.counts {
+		counts[id] = n
+	}
+	ruleStats.mu.Unlock()
+	if jsonOut {
+		out, _ := json.MarshalIndent(counts, "", "  ")
+		fmt.Fprintln(w, string(out))
+		return
+	}
+	if len(counts) == 0 {
+		fmt.Fprintln(w, "no rules made changes")
+		return
+	}
+	ids := make([]string, 0, len(counts))
+	for id := range counts {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		if counts[ids[i]] != counts[ids[j]] {
+			return counts[ids[i]] > counts[ids[j]]
+		}
+		return ids[i] < ids[j]
+	})
+	for _, id := range ids {
+		fmt.Fprintf(w, "%6d  %s\n", counts[id], id)
+	}
+}
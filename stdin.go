@@ -0,0 +1,56 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+// runStdin formats stdin to stdout when the target is "-". The rule set
+// comes from -stdin-filepath when given; otherwise the content is
+// sniffed, so piping tfvars content does not get block-oriented rules
+// applied to it.
+func runStdin() int {
+	src, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "barry: %s\n", err)
+		return 1
+	}
+	logical := opts.stdinFilepath
+	if logical == "" {
+		logical = sniffStdinName(src)
+	}
+	out, err := formatSource(logical, src)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "barry: %s\n", err)
+		return 1
+	}
+	os.Stdout.Write(out)
+	return 0
+}
+
+// sniffStdinName chooses a synthetic filename from the content's shape:
+// run blocks mean test-file rules, other top-level blocks mean .tf
+// rules, and bare assignments mean .tfvars rules.
+func sniffStdinName(src []byte) string {
+	f, diags := hclsyntax.ParseConfig(src, "stdin", hcl.InitialPos)
+	if diags.HasErrors() {
+		return "stdin.tf"
+	}
+	body, ok := f.Body.(*hclsyntax.Body)
+	if !ok {
+		return "stdin.tf"
+	}
+	for _, block := range body.Blocks {
+		if block.Type == "run" {
+			return "stdin.tftest.hcl"
+		}
+	}
+	if len(body.Blocks) == 0 && len(body.Attributes) > 0 {
+		return "stdin.tfvars"
+	}
+	return "stdin.tf"
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// styleVersions lists the known style generations, oldest first. A
+// generation bundles the rule-default changes that shipped in it, so a
+// repo pinning style_version keeps its formatting stable across binary
+// upgrades until it migrates deliberately.
+var styleVersions = []string{"2023.1", "2024.1"}
+
+// styleVersion is the generation this binary defaults to.
+var styleVersion = styleVersions[len(styleVersions)-1]
+
+// styleDefaultDeltas records, per generation, the rule defaults that
+// changed in it. A rule absent from every generation keeps its registry
+// default; a rule mentioned only in generations after the pin reverts
+// to off, its state before the change shipped.
+var styleDefaultDeltas = map[string]map[string]bool{
+	"2023.1": {},
+	"2024.1": {
+		"expand_one_line_blocks": true,
+	},
+}
+
+// styleDefaultFor resolves a rule's default under the pinned style
+// version. mentioned is false when no generation touches the rule.
+func styleDefaultFor(id, pin string) (value, mentioned bool) {
+	for _, v := range styleVersions {
+		val, ok := styleDefaultDeltas[v][id]
+		if !ok {
+			continue
+		}
+		mentioned = true
+		if compareStyleVersions(v, pin) <= 0 {
+			value = val
+		}
+	}
+	return value, mentioned
+}
+
+// compareStyleVersions orders YYYY.N style version strings.
+func compareStyleVersions(a, b string) int {
+	pa, pb := strings.SplitN(a, ".", 2), strings.SplitN(b, ".", 2)
+	for i := 0; i < 2; i++ {
+		var na, nb int
+		if i < len(pa) {
+			na, _ = strconv.Atoi(pa[i])
+		}
+		if i < len(pb) {
+			nb, _ = strconv.Atoi(pb[i])
+		}
+		if na != nb {
+			if na < nb {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+func knownStyleVersion(v string) bool {
+	return indexOf(styleVersions, v) >= 0
+}
+
+// runMigrateStyle implements the migrate-style subcommand: it rewrites
+// the config file's style_version pin to this binary's generation, the
+// deliberate act that lets the new defaults reformat the repo.
+func runMigrateStyle(args []string) int {
+	fs := flag.NewFlagSet("migrate-style", flag.ExitOnError)
+	configFile := fs.String("config", configFileName, "config file to update")
+	fs.Parse(args)
+
+	src, err := os.ReadFile(*configFile)
+	if os.IsNotExist(err) {
+		src = nil
+	} else if err != nil {
+		fmt.Fprintf(os.Stderr, "barry: %s\n", err)
+		return 1
+	}
+	f, diags := hclwrite.ParseConfig(src, *configFile, hcl.InitialPos)
+	if diags.HasErrors() {
+		fmt.Fprintf(os.Stderr, "barry: %s: %s\n", *configFile, diags)
+		return 1
+	}
+	previous := "unset"
+	if attr := f.Body().GetAttribute("style_version"); attr != nil {
+		previous = strings.TrimSpace(tokensText(attr.Expr().BuildTokens(nil)))
+	}
+	f.Body().SetAttributeValue("style_version", cty.StringVal(styleVersion))
+	if err := os.WriteFile(*configFile, f.Bytes(), 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "barry: %s\n", err)
+		return 1
+	}
+	fmt.Printf("%s: style_version %s -> %q\n", *configFile, previous, styleVersion)
+	return 0
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// runSuggest implements the suggest subcommand: rather than rewriting
+// files, it posts the formatter's changes as per-hunk suggested-change
+// review comments on a pull request, so authors can accept fixes from
+// the review UI.
+func runSuggest(args []string) int {
+	fs := flag.NewFlagSet("suggest", flag.ExitOnError)
+	github := fs.Bool("github", false, "post suggestions to a GitHub pull request")
+	gitlab := fs.Bool("gitlab", false, "post suggestions to a GitLab merge request")
+	bitbucket := fs.Bool("bitbucket", false, "post comments to a Bitbucket pull request")
+	repo := fs.String("repo", "", "repository in owner/name (or workspace/slug) form")
+	project := fs.String("project", "", "GitLab project ID or path")
+	pr := fs.Int("pr", 0, "pull or merge request number")
+	configFile := fs.String("config", "", "path to a config file (defaults to .barry.hcl if present)")
+	fs.Parse(args)
+
+	var err error
+	cfg, err = loadConfig(*configFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "barry: %s\n", err)
+		return 1
+	}
+	var provider vcsProvider
+	switch {
+	case *github:
+		provider, err = newGitHubProvider(*repo, *pr)
+	case *gitlab:
+		provider, err = newGitLabProvider(*project, *pr)
+	case *bitbucket:
+		provider, err = newBitbucketProvider(*repo, *pr)
+	default:
+		err = fmt.Errorf("suggest: no provider selected (try -github, -gitlab or -bitbucket)")
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "barry: %s\n", err)
+		return 1
+	}
+
+	targets := fs.Args()
+	if len(targets) == 0 {
+		targets = []string{"."}
+	}
+	var comments []reviewComment
+	for _, target := range targets {
+		files, err := lintTargetFiles(target)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "barry: %s\n", err)
+			return 1
+		}
+		for _, file := range files {
+			fileComments, err := suggestionsForFile(file)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "barry: %s: %s\n", file, err)
+				continue
+			}
+			comments = append(comments, fileComments...)
+		}
+	}
+	if len(comments) == 0 {
+		fmt.Println("nothing to suggest")
+		return 0
+	}
+	if err := provider.postSuggestions(comments); err != nil {
+		fmt.Fprintf(os.Stderr, "barry: %s\n", err)
+		return 1
+	}
+	fmt.Printf("posted %d suggestion(s) via %s\n", len(comments), provider.name())
+	return 0
+}
+
+// suggestionsForFile turns the formatter's changes to one file into
+// line-anchored review comments.
+func suggestionsForFile(path string) ([]reviewComment, error) {
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	out, err := formatSource(path, src)
+	if err != nil {
+		return nil, err
+	}
+	if bytes.Equal(src, out) {
+		return nil, nil
+	}
+	oldLines := splitLines(src)
+	var comments []reviewComment
+	for _, h := range diffHunks(src, out) {
+		start, end := h.OldStart, h.OldStart+h.OldLines-1
+		newText := h.NewText
+		if h.OldLines == 0 {
+			// A pure insertion cannot anchor to zero lines; fold the
+			// preceding line into the suggestion. At the very top of the
+			// file there is nothing to fold, so skip the hunk.
+			if h.OldStart <= 1 {
+				continue
+			}
+			start, end = h.OldStart-1, h.OldStart-1
+			newText = append([]string{oldLines[h.OldStart-2]}, newText...)
+		}
+		replacement := strings.Join(newText, "\n")
+		if len(newText) > 0 {
+			replacement += "\n"
+		}
+		comments = append(comments, reviewComment{
+			Path:        repoRelative(path),
+			StartLine:   start,
+			EndLine:     end,
+			Replacement: replacement,
+		})
+	}
+	return comments, nil
+}
@@ -0,0 +1,6 @@
+resource "aws_instance" "web" {
+  count = 2
+
+  ami           = "ami-0abcdef"
+  instance_type = "t3.micro"
+}
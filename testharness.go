@@ -0,0 +1,114 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// runTestdata implements the testdata subcommand, a golden-file harness
+// for rule authors - internal and plugin alike. Every input.* file with
+// a supported extension beneath the target directory is a case: the
+// formatter's output must match the sibling expected.* file. A case
+// directory may carry its own .barry.hcl, which applies to that case
+// only. -update regenerates the expected files instead of comparing.
+func runTestdata(args []string) int {
+	fs_ := flag.NewFlagSet("testdata", flag.ExitOnError)
+	update := fs_.Bool("update", false, "rewrite expected files from current output")
+	fs_.Parse(args)
+	dir := "testdata"
+	if fs_.NArg() > 0 {
+		dir = fs_.Arg(0)
+	}
+
+	failures, cases := 0, 0
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !strings.HasPrefix(d.Name(), "input.") || !hasSupportedExt(d.Name()) {
+			return nil
+		}
+		cases++
+		if ok := runTestdataCase(path, *update); !ok {
+			failures++
+		}
+		return nil
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "barry: %s\n", err)
+		return 1
+	}
+	if cases == 0 {
+		fmt.Fprintf(os.Stderr, "barry: no input.* fixtures under %s\n", dir)
+		return 1
+	}
+	if *update {
+		fmt.Printf("updated %d case(s)\n", cases)
+		return 0
+	}
+	fmt.Printf("%d case(s), %d failure(s)\n", cases, failures)
+	if failures > 0 {
+		return 1
+	}
+	return 0
+}
+
+func runTestdataCase(inputPath string, update bool) bool {
+	caseDir := filepath.Dir(inputPath)
+	expectedPath := filepath.Join(caseDir, strings.Replace(filepath.Base(inputPath), "input.", "expected.", 1))
+
+	// A per-case config overrides the global one for the duration of the
+	// case, so fixtures can exercise opt-in rules.
+	saved := cfg
+	if caseConfig := filepath.Join(caseDir, ".barry.hcl"); fileExists(caseConfig) {
+		loaded, err := loadConfig(caseConfig)
+		if err != nil {
+			fmt.Printf("FAIL %s: %s\n", caseDir, err)
+			return false
+		}
+		cfg = loaded
+	}
+	defer func() { cfg = saved }()
+
+	src, err := os.ReadFile(inputPath)
+	if err != nil {
+		fmt.Printf("FAIL %s: %s\n", caseDir, err)
+		return false
+	}
+	out, err := formatSource(inputPath, src)
+	if err != nil {
+		fmt.Printf("FAIL %s: %s\n", caseDir, err)
+		return false
+	}
+	if update {
+		if err := os.WriteFile(expectedPath, out, 0644); err != nil {
+			fmt.Printf("FAIL %s: %s\n", caseDir, err)
+			return false
+		}
+		return true
+	}
+	expected, err := os.ReadFile(expectedPath)
+	if err != nil {
+		fmt.Printf("FAIL %s: %s\n", caseDir, err)
+		return false
+	}
+	if !bytes.Equal(out, expected) {
+		fmt.Printf("FAIL %s\n", caseDir)
+		if text, err := diffBytes(expectedPath, expected, out); err == nil {
+			fmt.Print(text)
+		}
+		return false
+	}
+	fmt.Printf("ok   %s\n", caseDir)
+	return true
+}
+
+func fileExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir()
+}
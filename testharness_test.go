@@ -0,0 +1,18 @@
+package main
+
+import "testing"
+
+// TestGoldenFixtures drives the shipped fixture pairs through the same
+// path as the testdata subcommand, so `go test` and `barry testdata`
+// agree on the golden files.
+func TestGoldenFixtures(t *testing.T) {
+	cfg = defaultConfig()
+	for _, input := range []string{
+		"testdata/basic/input.tf",
+		"testdata/tfvars/input.tfvars",
+	} {
+		if ok := runTestdataCase(input, false); !ok {
+			t.Errorf("fixture %s does not match its expected file", input)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"bytes"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/terraform-linters/tflint-plugin-sdk/plugin"
+	"github.com/terraform-linters/tflint-plugin-sdk/tflint"
+)
+
+// runTflintRuleset serves barry's checks as a tflint ruleset plugin.
+// tflint starts the plugin binary itself, so teams point their .tflint.hcl
+// at the barry binary with this subcommand and get the same engine that
+// powers the CLI inside their existing pipeline.
+func runTflintRuleset() {
+	rules := []tflint.Rule{&formattingDriftRule{}}
+	for _, rule := range LintRules {
+		rules = append(rules, &barryTflintRule{rule: rule})
+	}
+	plugin.Serve(&plugin.ServeOpts{
+		RuleSet: &tflint.BuiltinRuleSet{
+			Name:    "barry",
+			Version: "0.1.0",
+			Rules:   rules,
+		},
+	})
+}
+
+// barryTflintRule adapts one of barry's lint rules to the tflint rule
+// interface.
+type barryTflintRule struct {
+	tflint.DefaultRule
+	rule *LintRule
+}
+
+func (r *barryTflintRule) Name() string              { return "barry_" + r.rule.ID }
+func (r *barryTflintRule) Enabled() bool             { return true }
+func (r *barryTflintRule) Severity() tflint.Severity { return tflint.WARNING }
+func (r *barryTflintRule) Link() string              { return "" }
+
+func (r *barryTflintRule) Check(runner tflint.Runner) error {
+	files, err := runner.GetFiles()
+	if err != nil {
+		return err
+	}
+	for name, file := range files {
+		body, ok := file.Body.(*hclsyntax.Body)
+		if !ok {
+			continue
+		}
+		for _, diag := range r.rule.Check(name, body) {
+			pos := hcl.Pos{Line: diag.Line, Column: diag.Column}
+			if err := runner.EmitIssue(r, diag.Message, hcl.Range{Filename: diag.File, Start: pos, End: pos}); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// formattingDriftRule reports files whose content differs from what the
+// formatter would produce.
+type formattingDriftRule struct {
+	tflint.DefaultRule
+}
+
+func (r *formattingDriftRule) Name() string              { return "barry_formatting" }
+func (r *formattingDriftRule) Enabled() bool             { return true }
+func (r *formattingDriftRule) Severity() tflint.Severity { return tflint.NOTICE }
+func (r *formattingDriftRule) Link() string              { return "" }
+
+func (r *formattingDriftRule) Check(runner tflint.Runner) error {
+	files, err := runner.GetFiles()
+	if err != nil {
+		return err
+	}
+	for name, file := range files {
+		out, err := formatSource(name, file.Bytes)
+		if err != nil {
+			continue
+		}
+		if !bytes.Equal(file.Bytes, out) {
+			pos := hcl.Pos{Line: 1, Column: 1}
+			if err := runner.EmitIssue(r, "file is not barry-formatted", hcl.Range{Filename: name, Start: pos, End: pos}); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
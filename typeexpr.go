@@ -0,0 +1,192 @@
+package main
+
+import (
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+)
+
+// formatStructuralType lays out object({...}) type constraints with one
+// attribute per line, relying on the later whole-file format pass for
+// indentation and = alignment. With the sort_type_keys rule enabled the
+// object keys are also ordered alphabetically, recursively through
+// nested object() constraints. Constraints carrying comments are left
+// alone rather than risk detaching them.
+func formatStructuralType(body *hclwrite.Body, attr *hclwrite.Attribute) {
+	toks := attr.Expr().BuildTokens(nil)
+	hasObject := false
+	for i, tok := range toks {
+		if tok.Type == hclsyntax.TokenComment {
+			return
+		}
+		if tok.Type == hclsyntax.TokenIdent && string(tok.Bytes) == "object" &&
+			i+1 < len(toks) && toks[i+1].Type == hclsyntax.TokenOParen {
+			hasObject = true
+		}
+	}
+	if !hasObject {
+		return
+	}
+	flat := make(hclwrite.Tokens, 0, len(toks))
+	for _, tok := range toks {
+		if tok.Type == hclsyntax.TokenNewline {
+			continue
+		}
+		flat = append(flat, tok)
+	}
+	if ruleEnabled("sort_type_keys") {
+		flat = sortTypeObjects(flat)
+	}
+	body.SetAttributeRaw("type", layoutTypeTokens(flat))
+}
+
+// layoutTypeTokens re-inserts newlines so every object() attribute sits
+// on its own line. Commas that separate object attributes become
+// newlines; commas elsewhere (tuple types, optional() defaults) are
+// kept.
+func layoutTypeTokens(toks hclwrite.Tokens) hclwrite.Tokens {
+	out := make(hclwrite.Tokens, 0, len(toks))
+	var objectFrame []bool
+	newline := func() {
+		out = append(out, &hclwrite.Token{Type: hclsyntax.TokenNewline, Bytes: []byte("\n")})
+	}
+	inObject := func() bool {
+		return len(objectFrame) > 0 && objectFrame[len(objectFrame)-1]
+	}
+	for i, tok := range toks {
+		switch tok.Type {
+		case hclsyntax.TokenOBrace:
+			isObject := i >= 2 && toks[i-1].Type == hclsyntax.TokenOParen &&
+				toks[i-2].Type == hclsyntax.TokenIdent && string(toks[i-2].Bytes) == "object"
+			objectFrame = append(objectFrame, isObject)
+			out = append(out, tok)
+			if isObject {
+				newline()
+			}
+		case hclsyntax.TokenCBrace:
+			if inObject() {
+				newline()
+			}
+			if len(objectFrame) > 0 {
+				objectFrame = objectFrame[:len(objectFrame)-1]
+			}
+			out = append(out, tok)
+		case hclsyntax.TokenOParen, hclsyntax.TokenOBrack:
+			objectFrame = append(objectFrame, false)
+			out = append(out, tok)
+		case hclsyntax.TokenCParen, hclsyntax.TokenCBrack:
+			if len(objectFrame) > 0 {
+				objectFrame = objectFrame[:len(objectFrame)-1]
+			}
+			out = append(out, tok)
+		case hclsyntax.TokenComma:
+			if inObject() {
+				newline()
+			} else {
+				out = append(out, tok)
+			}
+		default:
+			out = append(out, tok)
+		}
+	}
+	return out
+}
+
+// sortTypeObjects alphabetizes the attributes of every object({...})
+// constraint in the token run, innermost first.
+func sortTypeObjects(toks hclwrite.Tokens) hclwrite.Tokens {
+	out := make(hclwrite.Tokens, 0, len(toks))
+	for i := 0; i < len(toks); i++ {
+		tok := toks[i]
+		if tok.Type == hclsyntax.TokenIdent && string(tok.Bytes) == "object" &&
+			i+2 < len(toks) && toks[i+1].Type == hclsyntax.TokenOParen &&
+			toks[i+2].Type == hclsyntax.TokenOBrace {
+			end := matchingClose(toks, i+2)
+			if end < 0 {
+				out = append(out, tok)
+				continue
+			}
+			inner := sortTypeObjects(toks[i+3 : end])
+			out = append(out, tok, toks[i+1], toks[i+2])
+			out = append(out, joinTypeElements(sortTypeElements(splitTypeElements(inner)))...)
+			out = append(out, toks[end])
+			i = end
+			continue
+		}
+		out = append(out, tok)
+	}
+	return out
+}
+
+// matchingClose returns the index of the token closing the bracketing
+// token at open, or -1 if the run is not well nested.
+func matchingClose(toks hclwrite.Tokens, open int) int {
+	depth := 0
+	for i := open; i < len(toks); i++ {
+		switch toks[i].Type {
+		case hclsyntax.TokenOBrace, hclsyntax.TokenOParen, hclsyntax.TokenOBrack:
+			depth++
+		case hclsyntax.TokenCBrace, hclsyntax.TokenCParen, hclsyntax.TokenCBrack:
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// splitTypeElements splits object constraint contents into key = type
+// runs on top-level commas.
+func splitTypeElements(toks hclwrite.Tokens) []hclwrite.Tokens {
+	var elems []hclwrite.Tokens
+	var cur hclwrite.Tokens
+	depth := 0
+	for _, tok := range toks {
+		switch tok.Type {
+		case hclsyntax.TokenOBrace, hclsyntax.TokenOParen, hclsyntax.TokenOBrack:
+			depth++
+		case hclsyntax.TokenCBrace, hclsyntax.TokenCParen, hclsyntax.TokenCBrack:
+			depth--
+		}
+		if depth == 0 && tok.Type == hclsyntax.TokenComma {
+			if len(cur) > 0 {
+				elems = append(elems, cur)
+				cur = nil
+			}
+			continue
+		}
+		cur = append(cur, tok)
+	}
+	if len(cur) > 0 {
+		elems = append(elems, cur)
+	}
+	return elems
+}
+
+func sortTypeElements(elems []hclwrite.Tokens) []hclwrite.Tokens {
+	keys := make([]string, len(elems))
+	for i, elem := range elems {
+		key, ok := objectElementKey(elem)
+		if !ok {
+			return elems
+		}
+		keys[i] = key
+	}
+	order := sortOrder(keys)
+	out := make([]hclwrite.Tokens, len(elems))
+	for i, idx := range order {
+		out[i] = elems[idx]
+	}
+	return out
+}
+
+func joinTypeElements(elems []hclwrite.Tokens) hclwrite.Tokens {
+	var out hclwrite.Tokens
+	for i, elem := range elems {
+		if i > 0 {
+			out = append(out, &hclwrite.Token{Type: hclsyntax.TokenComma, Bytes: []byte(",")})
+		}
+		out = append(out, elem...)
+	}
+	return out
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// A reviewComment is one proposed replacement anchored to a line range
+// of a file on the pull request's head.
+type reviewComment struct {
+	Path      string
+	StartLine int
+	EndLine   int
+	// Replacement is the text the lines should become; providers wrap it
+	// in their own suggestion syntax.
+	Replacement string
+}
+
+// A vcsProvider posts barry's proposed fixes onto a pull or merge
+// request in whatever form the hosting service supports.
+type vcsProvider interface {
+	name() string
+	postSuggestions(comments []reviewComment) error
+}
+
+// vcsRequest performs one JSON API call against a hosting service and
+// decodes the response into out (when out is non-nil).
+func vcsRequest(method, url string, headers map[string]string, payload, out interface{}) error {
+	var body io.Reader
+	if payload != nil {
+		encoded, err := json.Marshal(payload)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(encoded)
+	}
+	req, err := http.NewRequest(method, url, body)
+	if err != nil {
+		return err
+	}
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	respBody, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("%s: %s", resp.Status, bytes.TrimSpace(respBody))
+	}
+	if out != nil {
+		return json.Unmarshal(respBody, out)
+	}
+	return nil
+}
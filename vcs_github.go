@@ -0,0 +1,72 @@
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+// githubProvider posts suggested-change review comments through the
+// GitHub pull request review API, so authors can accept fixes from the
+// PR UI.
+type githubProvider struct {
+	repo   string // owner/name
+	pr     int
+	token  string
+	apiURL string
+}
+
+func newGitHubProvider(repo string, pr int) (*githubProvider, error) {
+	if repo == "" {
+		repo = os.Getenv("GITHUB_REPOSITORY")
+	}
+	token := os.Getenv("GITHUB_TOKEN")
+	if repo == "" || pr == 0 || token == "" {
+		return nil, fmt.Errorf("github: need -repo (or GITHUB_REPOSITORY), -pr and GITHUB_TOKEN")
+	}
+	apiURL := os.Getenv("GITHUB_API_URL")
+	if apiURL == "" {
+		apiURL = "https://api.github.com"
+	}
+	return &githubProvider{repo: repo, pr: pr, token: token, apiURL: apiURL}, nil
+}
+
+func (p *githubProvider) name() string { return "github" }
+
+func (p *githubProvider) postSuggestions(comments []reviewComment) error {
+	type ghComment struct {
+		Path      string `json:"path"`
+		Body      string `json:"body"`
+		Line      int    `json:"line"`
+		Side      string `json:"side"`
+		StartLine int    `json:"start_line,omitempty"`
+		StartSide string `json:"start_side,omitempty"`
+	}
+	ghComments := make([]ghComment, 0, len(comments))
+	for _, c := range comments {
+		gc := ghComment{
+			Path: c.Path,
+			Body: "```suggestion\n" + c.Replacement + "```",
+			Line: c.EndLine,
+			Side: "RIGHT",
+		}
+		if c.StartLine < c.EndLine {
+			gc.StartLine = c.StartLine
+			gc.StartSide = "RIGHT"
+		}
+		ghComments = append(ghComments, gc)
+	}
+	payload := map[string]interface{}{
+		"event":    "COMMENT",
+		"body":     "barry formatting suggestions",
+		"comments": ghComments,
+	}
+	url := fmt.Sprintf("%s/repos/%s/pulls/%d/reviews", p.apiURL, p.repo, p.pr)
+	headers := map[string]string{
+		"Authorization": "Bearer " + p.token,
+		"Accept":        "application/vnd.github+json",
+	}
+	if err := vcsRequest("POST", url, headers, payload, nil); err != nil {
+		return fmt.Errorf("github: %s", err)
+	}
+	return nil
+}
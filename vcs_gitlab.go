@@ -0,0 +1,122 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// gitlabProvider posts positioned merge request discussions carrying
+// GitLab suggestion blocks, which reviewers can apply from the MR UI.
+type gitlabProvider struct {
+	baseURL string
+	project string
+	mr      int
+	token   string
+}
+
+func newGitLabProvider(project string, mr int) (*gitlabProvider, error) {
+	if project == "" {
+		project = os.Getenv("CI_PROJECT_ID")
+	}
+	if mr == 0 {
+		fmt.Sscanf(os.Getenv("CI_MERGE_REQUEST_IID"), "%d", &mr)
+	}
+	token := os.Getenv("GITLAB_TOKEN")
+	if project == "" || mr == 0 || token == "" {
+		return nil, fmt.Errorf("gitlab: need -project (or CI_PROJECT_ID), -pr (or CI_MERGE_REQUEST_IID) and GITLAB_TOKEN")
+	}
+	baseURL := os.Getenv("CI_SERVER_URL")
+	if baseURL == "" {
+		baseURL = "https://gitlab.com"
+	}
+	return &gitlabProvider{baseURL: baseURL, project: project, mr: mr, token: token}, nil
+}
+
+func (p *gitlabProvider) name() string { return "gitlab" }
+
+func (p *gitlabProvider) postSuggestions(comments []reviewComment) error {
+	headers := map[string]string{"PRIVATE-TOKEN": p.token}
+	api := fmt.Sprintf("%s/api/v4/projects/%s/merge_requests/%d",
+		p.baseURL, url.PathEscape(p.project), p.mr)
+
+	// Positioned discussions need the MR's diff refs.
+	var mr struct {
+		DiffRefs struct {
+			BaseSHA  string `json:"base_sha"`
+			HeadSHA  string `json:"head_sha"`
+			StartSHA string `json:"start_sha"`
+		} `json:"diff_refs"`
+	}
+	if err := vcsRequest("GET", api, headers, nil, &mr); err != nil {
+		return fmt.Errorf("gitlab: %s", err)
+	}
+	for _, c := range comments {
+		body := fmt.Sprintf("```suggestion:-0+%d\n%s```", c.EndLine-c.StartLine, c.Replacement)
+		payload := map[string]interface{}{
+			"body": body,
+			"position": map[string]interface{}{
+				"position_type": "text",
+				"base_sha":      mr.DiffRefs.BaseSHA,
+				"head_sha":      mr.DiffRefs.HeadSHA,
+				"start_sha":     mr.DiffRefs.StartSHA,
+				"new_path":      c.Path,
+				"old_path":      c.Path,
+				"new_line":      c.StartLine,
+			},
+		}
+		if err := vcsRequest("POST", api+"/discussions", headers, payload, nil); err != nil {
+			return fmt.Errorf("gitlab: %s: %s", c.Path, err)
+		}
+	}
+	return nil
+}
+
+// bitbucketProvider posts inline pull request comments with the proposed
+// replacement as a fenced block; Bitbucket has no applyable suggestion
+// syntax, so the comment shows the fix instead.
+type bitbucketProvider struct {
+	workspace string
+	repo      string
+	pr        int
+	token     string
+}
+
+func newBitbucketProvider(repo string, pr int) (*bitbucketProvider, error) {
+	workspace := os.Getenv("BITBUCKET_WORKSPACE")
+	slug := os.Getenv("BITBUCKET_REPO_SLUG")
+	if repo != "" {
+		if i := strings.IndexByte(repo, '/'); i > 0 {
+			workspace, slug = repo[:i], repo[i+1:]
+		}
+	}
+	if pr == 0 {
+		fmt.Sscanf(os.Getenv("BITBUCKET_PR_ID"), "%d", &pr)
+	}
+	token := os.Getenv("BITBUCKET_TOKEN")
+	if workspace == "" || slug == "" || pr == 0 || token == "" {
+		return nil, fmt.Errorf("bitbucket: need -repo workspace/slug (or BITBUCKET_WORKSPACE and BITBUCKET_REPO_SLUG), -pr (or BITBUCKET_PR_ID) and BITBUCKET_TOKEN")
+	}
+	return &bitbucketProvider{workspace: workspace, repo: slug, pr: pr, token: token}, nil
+}
+
+func (p *bitbucketProvider) name() string { return "bitbucket" }
+
+func (p *bitbucketProvider) postSuggestions(comments []reviewComment) error {
+	headers := map[string]string{"Authorization": "Bearer " + p.token}
+	api := fmt.Sprintf("https://api.bitbucket.org/2.0/repositories/%s/%s/pullrequests/%d/comments",
+		p.workspace, p.repo, p.pr)
+	for _, c := range comments {
+		raw := fmt.Sprintf("barry would reformat lines %d-%d to:\n\n```hcl\n%s```",
+			c.StartLine, c.EndLine, c.Replacement)
+		payload := map[string]interface{}{
+			"content": map[string]string{"raw": raw},
+			"inline":  map[string]interface{}{"path": c.Path, "to": c.EndLine},
+		}
+		if err := vcsRequest("POST", api, headers, payload, nil); err != nil {
+			return fmt.Errorf("bitbucket: %s: %s", c.Path, err)
+		}
+	}
+	return nil
+}
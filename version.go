@@ -0,0 +1,46 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"runtime"
+)
+
+// Build metadata, stamped at link time:
+//
+//	go build -ldflags "-X main.version=v0.2.0 -X main.commit=$(git rev-parse HEAD) -X main.buildDate=..."
+var (
+	version   = "dev"
+	commit    = "unknown"
+	buildDate = "unknown"
+)
+
+// supportedDialects lists the configuration dialects this binary can
+// format.
+var supportedDialects = []string{dialectTerraform, dialectOpenTofu}
+
+// runVersion implements the version subcommand.
+func runVersion(args []string) int {
+	fs := flag.NewFlagSet("version", flag.ExitOnError)
+	jsonOut := fs.Bool("json", false, "emit version information as JSON")
+	fs.Parse(args)
+	if *jsonOut {
+		out, _ := json.MarshalIndent(struct {
+			Version      string   `json:"version"`
+			Commit       string   `json:"commit"`
+			BuildDate    string   `json:"build_date"`
+			StyleVersion string   `json:"style_version"`
+			Dialects     []string `json:"dialects"`
+			GoVersion    string   `json:"go_version"`
+		}{version, commit, buildDate, styleVersion, supportedDialects, runtime.Version()}, "", "  ")
+		fmt.Println(string(out))
+		return 0
+	}
+	fmt.Printf("barry %s (%s, built %s)\n", version, commit, buildDate)
+	fmt.Printf("style version %s\n", styleVersion)
+	for _, d := range supportedDialects {
+		fmt.Printf("dialect: %s\n", d)
+	}
+	return 0
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/imports/wasi_snapshot_preview1"
+	"github.com/tetratelabs/wazero/sys"
+)
+
+// WASM plugins speak the same JSON protocol as exec plugins but run
+// sandboxed as WASI commands inside a wazero runtime: the request
+// arrives on stdin, the response leaves on stdout, and the module gets
+// no filesystem or network access. Alongside the source text the
+// request carries the lexed token stream, so rules can work on
+// structure without an HCL parser, and responses may return byte-offset
+// text edits instead of full content.
+
+// pluginToken is one lexed token in a plugin request.
+type pluginToken struct {
+	Type  string `json:"type"`
+	Bytes string `json:"bytes"`
+	Start int    `json:"start"`
+	End   int    `json:"end"`
+}
+
+// A TextEdit replaces the bytes in [Start, End) with Replacement.
+type TextEdit struct {
+	Start       int    `json:"start"`
+	End         int    `json:"end"`
+	Replacement string `json:"replacement"`
+}
+
+// lexPluginTokens lexes src for inclusion in a plugin request. Lex
+// errors are ignored here; the plugin sees whatever tokens there are.
+func lexPluginTokens(path string, src []byte) []pluginToken {
+	toks, _ := hclsyntax.LexConfig(src, path, hcl.InitialPos)
+	out := make([]pluginToken, 0, len(toks))
+	for _, tok := range toks {
+		out = append(out, pluginToken{
+			Type:  tok.Type.String(),
+			Bytes: string(tok.Bytes),
+			Start: tok.Range.Start.Byte,
+			End:   tok.Range.End.Byte,
+		})
+	}
+	return out
+}
+
+// applyTextEdits applies non-overlapping byte-offset edits to src,
+// splicing from the end so earlier offsets stay valid.
+func applyTextEdits(src []byte, edits []TextEdit) ([]byte, error) {
+	sorted := append([]TextEdit(nil), edits...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Start > sorted[j].Start
+	})
+	out := append([]byte(nil), src...)
+	prevStart := len(src) + 1
+	for _, edit := range sorted {
+		if edit.Start < 0 || edit.End > len(src) || edit.Start > edit.End {
+			return nil, fmt.Errorf("edit range [%d, %d) out of bounds", edit.Start, edit.End)
+		}
+		if edit.End > prevStart {
+			return nil, fmt.Errorf("overlapping edit at offset %d", edit.Start)
+		}
+		prevStart = edit.Start
+		out = append(out[:edit.Start], append([]byte(edit.Replacement), out[edit.End:]...)...)
+	}
+	return out, nil
+}
+
+// wasmPluginFiles lists the *.wasm modules in the plugin directory.
+func wasmPluginFiles() ([]string, error) {
+	if cfg.PluginDir == "" {
+		return nil, nil
+	}
+	entries, err := os.ReadDir(cfg.PluginDir)
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".wasm") {
+			files = append(files, filepath.Join(cfg.PluginDir, entry.Name()))
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// runWasmPlugins pipes a file through every *.wasm plugin in turn,
+// mirroring runPlugins for native executables.
+func runWasmPlugins(path string, src []byte) ([]byte, []Diagnostic, error) {
+	files, err := wasmPluginFiles()
+	if err != nil || len(files) == 0 {
+		return src, nil, err
+	}
+	ctx := context.Background()
+	runtime := wazero.NewRuntime(ctx)
+	defer runtime.Close(ctx)
+	wasi_snapshot_preview1.MustInstantiate(ctx, runtime)
+
+	var diags []Diagnostic
+	for _, file := range files {
+		wasmBytes, err := os.ReadFile(file)
+		if err != nil {
+			return src, diags, err
+		}
+		req, err := json.Marshal(pluginRequest{
+			Version: pluginProtocolVersion,
+			Path:    path,
+			Source:  string(src),
+			Tokens:  lexPluginTokens(path, src),
+		})
+		if err != nil {
+			return src, diags, err
+		}
+		var stdout, stderr bytes.Buffer
+		config := wazero.NewModuleConfig().
+			WithName(filepath.Base(file)).
+			WithStdin(bytes.NewReader(req)).
+			WithStdout(&stdout).
+			WithStderr(&stderr)
+		mod, err := runtime.InstantiateWithConfig(ctx, wasmBytes, config)
+		if err != nil {
+			if exitErr, ok := err.(*sys.ExitError); !ok || exitErr.ExitCode() != 0 {
+				return src, diags, fmt.Errorf("wasm plugin %s: %s (%s)", filepath.Base(file), err, strings.TrimSpace(stderr.String()))
+			}
+		} else {
+			mod.Close(ctx)
+		}
+		var resp pluginResponse
+		if err := json.Unmarshal(stdout.Bytes(), &resp); err != nil {
+			return src, diags, fmt.Errorf("wasm plugin %s: invalid response: %s", filepath.Base(file), err)
+		}
+		src, diags, err = applyPluginResponse(filepath.Base(file), path, src, resp, diags)
+		if err != nil {
+			return src, diags, err
+		}
+	}
+	return src, diags, nil
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+)
+
+// watchInterval is how often watch mode rescans its targets for
+// modified files. Polling keeps barry dependency-free; config files are
+// small and the scan is cheap.
+const watchInterval = 2 * time.Second
+
+// runWatch formats targets continuously: an initial pass, then a rescan
+// whenever file modification times change. With -metrics-addr set, the
+// Prometheus counters are served alongside.
+func runWatch(targets []string) int {
+	if opts.metricsAddr != "" {
+		mux := http.NewServeMux()
+		mux.HandleFunc("/metrics", handleMetrics)
+		go func() {
+			if err := http.ListenAndServe(opts.metricsAddr, mux); err != nil {
+				fmt.Fprintf(os.Stderr, "barry: metrics: %s\n", err)
+			}
+		}()
+	}
+	mtimes := make(map[string]time.Time)
+	for {
+		for _, target := range targets {
+			files, err := lintTargetFiles(target)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "barry: %s\n", err)
+				continue
+			}
+			for _, file := range files {
+				info, err := os.Stat(file)
+				if err != nil {
+					delete(mtimes, file)
+					continue
+				}
+				if prev, ok := mtimes[file]; ok && !info.ModTime().After(prev) {
+					continue
+				}
+				start := time.Now()
+				changed, err := processFile(file)
+				metrics.observe(time.Since(start), changed, err)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "barry: %s: %s\n", file, err)
+				}
+				// Re-stat so the write barry just did does not count as
+				// a fresh modification next tick.
+				if info, err := os.Stat(file); err == nil {
+					mtimes[file] = info.ModTime()
+				}
+			}
+		}
+		time.Sleep(watchInterval)
+	}
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/hashicorp/hcl/v2/gohcl"
+	"github.com/hashicorp/hcl/v2/hclparse"
+)
+
+// A Workspace lists the Terraform roots of a monorepo, each with its
+// own config and dialect, so platform teams can run barry across
+// hundreds of roots in one invocation with a consolidated report.
+type Workspace struct {
+	Roots []WorkspaceRoot `hcl:"root,block"`
+}
+
+// WorkspaceRoot is one root entry in the workspace file.
+type WorkspaceRoot struct {
+	Path    string `hcl:"path,label"`
+	Config  string `hcl:"config,optional"`
+	Dialect string `hcl:"dialect,optional"`
+}
+
+// loadWorkspace parses a workspace manifest.
+func loadWorkspace(path string) (*Workspace, error) {
+	parser := hclparse.NewParser()
+	f, diags := parser.ParseHCLFile(path)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	var ws Workspace
+	if diags := gohcl.DecodeBody(f.Body, nil, &ws); diags.HasErrors() {
+		return nil, diags
+	}
+	for _, root := range ws.Roots {
+		if root.Dialect != "" {
+			if err := validDialect(root.Dialect); err != nil {
+				return nil, fmt.Errorf("root %q: %w", root.Path, err)
+			}
+		}
+	}
+	return &ws, nil
+}
+
+// runWorkspace processes every root in the manifest with that root's
+// config and dialect, printing a consolidated per-root summary. The
+// usual flags (check, write, diff) apply within each root.
+func runWorkspace(path string) int {
+	ws, err := loadWorkspace(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "barry: %s\n", err)
+		return 1
+	}
+	if len(ws.Roots) == 0 {
+		fmt.Fprintf(os.Stderr, "barry: workspace %s lists no roots\n", path)
+		return 1
+	}
+	savedCfg, savedDialect := cfg, opts.dialect
+	defer func() { cfg, opts.dialect = savedCfg, savedDialect }()
+
+	exit := 0
+	for _, root := range ws.Roots {
+		configPath := root.Config
+		if configPath == "" {
+			if candidate := filepath.Join(root.Path, configFileName); fileExists(candidate) {
+				configPath = candidate
+			}
+		}
+		rootCfg, err := loadConfig(configPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "barry: root %s: %s\n", root.Path, err)
+			exit = 1
+			continue
+		}
+		cfg = rootCfg
+		opts.dialect = savedDialect
+		if root.Dialect != "" {
+			opts.dialect = root.Dialect
+		}
+
+		files, err := lintTargetFiles(root.Path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "barry: root %s: %s\n", root.Path, err)
+			exit = 1
+			continue
+		}
+		changed, errors := 0, 0
+		for _, file := range files {
+			didChange, err := processFile(file)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "barry: %s: %s\n", file, err)
+				errors++
+				continue
+			}
+			if didChange {
+				changed++
+			}
+		}
+		status := "ok"
+		switch {
+		case errors > 0:
+			status = fmt.Sprintf("%d error(s)", errors)
+			exit = 1
+		case changed > 0:
+			status = fmt.Sprintf("%d unformatted", changed)
+			if opts.check && exit == 0 {
+				exit = 2
+			}
+		}
+		fmt.Printf("root %-40s %3d file(s)  %s\n", root.Path, len(files), status)
+	}
+	return exit
+}
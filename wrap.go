@@ -0,0 +1,251 @@
+package main
+
+import (
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+)
+
+// wrapLongAttr breaks an attribute whose rendered line would exceed the
+// configured max_line_length, spreading the elements of its outermost
+// call, list or object literal across lines. Elements that still do not
+// fit are wrapped recursively. Expressions that already span lines, or
+// that carry heredocs or comments, are left as their author shaped them.
+func wrapLongAttr(body *hclwrite.Body, name string, attr *hclwrite.Attribute, indent int) {
+	width := cfg.MaxLineLength
+	if width <= 0 {
+		return
+	}
+	toks := attr.Expr().BuildTokens(nil)
+	for _, tok := range toks {
+		switch tok.Type {
+		case hclsyntax.TokenNewline, hclsyntax.TokenOHeredoc, hclsyntax.TokenComment:
+			return
+		}
+	}
+	used := indent + len(name) + 3 // "name = "
+	out, changed := wrapTokens(toks, used, width, indent)
+	if changed {
+		countRule("max_line_length")
+		body.SetAttributeRaw(name, out)
+	}
+}
+
+// collapseShortAttr pulls a multi-line list or object holding a single
+// short element back onto one line, the inverse of wrapLongAttr for the
+// tfvars-heavy repositories where one-entry collections dominate. The
+// collapsed line must fit within max_line_length (or 80 columns when no
+// width is configured).
+func collapseShortAttr(body *hclwrite.Body, name string, attr *hclwrite.Attribute, indent int) {
+	width := cfg.MaxLineLength
+	if width <= 0 {
+		width = 80
+	}
+	toks := attr.Expr().BuildTokens(nil)
+	var closeType hclsyntax.TokenType
+	switch toks[0].Type {
+	case hclsyntax.TokenOBrack:
+		closeType = hclsyntax.TokenCBrack
+	case hclsyntax.TokenOBrace:
+		closeType = hclsyntax.TokenCBrace
+	default:
+		return
+	}
+	if matchingClose(toks, 0) != len(toks)-1 {
+		return
+	}
+	// Count elements before flattening anything: splitCollection honours
+	// newline separators as well as commas, so a newline-separated
+	// multi-element collection is never collapsed into a single line
+	// with no separators at all.
+	elems, multiline, ok := splitCollection(attr, toks[0].Type, closeType)
+	if !ok || !multiline || len(elems) != 1 {
+		return
+	}
+	flat := make(hclwrite.Tokens, 0, len(toks))
+	for _, tok := range toks {
+		switch tok.Type {
+		case hclsyntax.TokenNewline:
+			continue
+		case hclsyntax.TokenComment, hclsyntax.TokenOHeredoc:
+			return
+		}
+		flat = append(flat, tok)
+	}
+	if len(flat) < 3 {
+		return
+	}
+	if trailing := flat[len(flat)-2]; trailing.Type == hclsyntax.TokenComma {
+		flat = append(flat[:len(flat)-2], flat[len(flat)-1])
+	}
+	if indent+len(name)+3+tokensWidth(flat) > width {
+		return
+	}
+	countRule("collapse_short_collections")
+	body.SetAttributeRaw(name, flat)
+}
+
+// tokensWidth is the rendered width of a single-line token run.
+func tokensWidth(toks hclwrite.Tokens) int {
+	w := 0
+	for _, tok := range toks {
+		w += tok.SpacesBefore + len(tok.Bytes)
+	}
+	return w
+}
+
+// wrapTokens spreads the outermost group in toks across lines when the
+// line would not fit. used is the width already consumed ahead of the
+// expression on its first line; indent is the column the expression
+// starts at, so continuation elements sit one level deeper.
+func wrapTokens(toks hclwrite.Tokens, used, width, indent int) (hclwrite.Tokens, bool) {
+	if used+tokensWidth(toks) <= width {
+		return toks, false
+	}
+	if out, ok := wrapTernary(toks); ok {
+		return out, true
+	}
+	if out, ok := wrapForExpr(toks); ok {
+		return out, true
+	}
+	open := -1
+	depth := 0
+	for i, tok := range toks {
+		switch tok.Type {
+		case hclsyntax.TokenOParen, hclsyntax.TokenOBrack, hclsyntax.TokenOBrace:
+			if depth == 0 && open < 0 {
+				open = i
+			}
+			depth++
+		case hclsyntax.TokenCParen, hclsyntax.TokenCBrack, hclsyntax.TokenCBrace:
+			depth--
+		}
+	}
+	if open < 0 {
+		return toks, false
+	}
+	close := matchingClose(toks, open)
+	if close <= open+1 {
+		return toks, false
+	}
+	elems := splitTypeElements(toks[open+1 : close])
+	if len(elems) == 0 {
+		return toks, false
+	}
+	out := make(hclwrite.Tokens, 0, len(toks)+2*len(elems))
+	out = append(out, toks[:open+1]...)
+	newline := func() {
+		out = append(out, &hclwrite.Token{Type: hclsyntax.TokenNewline, Bytes: []byte("\n")})
+	}
+	for i, elem := range elems {
+		newline()
+		wrapped, _ := wrapTokens(copyTokens(elem), indent+2, width, indent+2)
+		out = append(out, wrapped...)
+		if i < len(elems)-1 {
+			out = append(out, &hclwrite.Token{Type: hclsyntax.TokenComma, Bytes: []byte(",")})
+		}
+	}
+	newline()
+	out = append(out, toks[close:]...)
+	return out, true
+}
+
+// wrapTernary lays a top-level conditional out with the condition, the
+// ? branch and the : branch each on their own line, inside the
+// multi-line parenthesization that licenses the newlines.
+func wrapTernary(toks hclwrite.Tokens) (hclwrite.Tokens, bool) {
+	q, c := -1, -1
+	depth, ternaries := 0, 0
+	for i, tok := range toks {
+		switch tok.Type {
+		case hclsyntax.TokenOParen, hclsyntax.TokenOBrack, hclsyntax.TokenOBrace:
+			depth++
+		case hclsyntax.TokenCParen, hclsyntax.TokenCBrack, hclsyntax.TokenCBrace:
+			depth--
+		case hclsyntax.TokenQuestion:
+			if depth == 0 {
+				if q < 0 {
+					q = i
+				} else if c < 0 {
+					ternaries++
+				}
+			}
+		case hclsyntax.TokenColon:
+			if depth == 0 && q >= 0 && c < 0 {
+				if ternaries > 0 {
+					ternaries--
+				} else {
+					c = i
+				}
+			}
+		}
+	}
+	if q <= 0 || c <= q+1 || c >= len(toks)-1 {
+		return nil, false
+	}
+	newline := &hclwrite.Token{Type: hclsyntax.TokenNewline, Bytes: []byte("\n")}
+	out := hclwrite.Tokens{{Type: hclsyntax.TokenOParen, Bytes: []byte("(")}, newline}
+	out = append(out, copyTokens(toks[:q])...)
+	out = append(out, newline, toks[q])
+	out = append(out, toks[q+1:c]...)
+	out = append(out, newline, toks[c])
+	out = append(out, toks[c+1:]...)
+	out = append(out, newline, &hclwrite.Token{Type: hclsyntax.TokenCParen, Bytes: []byte(")")})
+	return out, true
+}
+
+// wrapForExpr lays a whole-expression for comprehension out with the
+// intro clause, the value expression and any if filter on separate
+// lines.
+func wrapForExpr(toks hclwrite.Tokens) (hclwrite.Tokens, bool) {
+	if len(toks) < 4 {
+		return nil, false
+	}
+	switch toks[0].Type {
+	case hclsyntax.TokenOBrack, hclsyntax.TokenOBrace:
+	default:
+		return nil, false
+	}
+	if toks[1].Type != hclsyntax.TokenIdent || string(toks[1].Bytes) != "for" {
+		return nil, false
+	}
+	end := matchingClose(toks, 0)
+	if end != len(toks)-1 {
+		return nil, false
+	}
+	colon, filter := -1, -1
+	depth := 0
+	for i := 1; i < end; i++ {
+		switch toks[i].Type {
+		case hclsyntax.TokenOParen, hclsyntax.TokenOBrack, hclsyntax.TokenOBrace:
+			depth++
+		case hclsyntax.TokenCParen, hclsyntax.TokenCBrack, hclsyntax.TokenCBrace:
+			depth--
+		case hclsyntax.TokenColon:
+			if depth == 0 && colon < 0 {
+				colon = i
+			}
+		case hclsyntax.TokenIdent:
+			if depth == 0 && colon >= 0 && filter < 0 && string(toks[i].Bytes) == "if" {
+				filter = i
+			}
+		}
+	}
+	if colon < 0 {
+		return nil, false
+	}
+	newline := &hclwrite.Token{Type: hclsyntax.TokenNewline, Bytes: []byte("\n")}
+	out := hclwrite.Tokens{toks[0], newline}
+	out = append(out, copyTokens(toks[1:colon+1])...)
+	out = append(out, newline)
+	valueEnd := end
+	if filter > 0 {
+		valueEnd = filter
+	}
+	out = append(out, copyTokens(toks[colon+1:valueEnd])...)
+	if filter > 0 {
+		out = append(out, newline)
+		out = append(out, copyTokens(toks[filter:end])...)
+	}
+	out = append(out, newline, toks[end])
+	return out, true
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+)
+
+func TestCollapseShortAttrLeavesMultiElementObjects(t *testing.T) {
+	cfg = defaultConfig()
+	cfg.Rules = map[string]bool{"collapse_short_collections": true}
+	defer func() { cfg = defaultConfig() }()
+
+	src := []byte(`resource "aws_instance" "web" {
+  tags = {
+    b = 1
+    a = 2
+  }
+}
+`)
+	out, err := formatSource("main.tf", src)
+	if err != nil {
+		t.Fatalf("formatSource: %s", err)
+	}
+	// The newline-separated two-element object must not be collapsed,
+	// and whatever comes out must still be valid HCL.
+	if _, diags := hclwrite.ParseConfig(out, "out.tf", hcl.InitialPos); diags.HasErrors() {
+		t.Fatalf("output does not re-parse: %s\n%s", diags, out)
+	}
+	if !bytes.Contains(out, []byte("= 1\n")) {
+		t.Fatalf("multi-element object was collapsed onto one line:\n%s", out)
+	}
+}
+
+func TestCollapseShortAttrCollapsesSingleElement(t *testing.T) {
+	cfg = defaultConfig()
+	cfg.Rules = map[string]bool{"collapse_short_collections": true}
+	defer func() { cfg = defaultConfig() }()
+
+	src := []byte(`resource "aws_instance" "web" {
+  tags = {
+    name = "web"
+  }
+}
+`)
+	out, err := formatSource("main.tf", src)
+	if err != nil {
+		t.Fatalf("formatSource: %s", err)
+	}
+	if _, diags := hclwrite.ParseConfig(out, "out.tf", hcl.InitialPos); diags.HasErrors() {
+		t.Fatalf("output does not re-parse: %s\n%s", diags, out)
+	}
+	if !bytes.Contains(out, []byte(`tags = { name = "web" }`)) {
+		t.Fatalf("single-element object was not collapsed:\n%s", out)
+	}
+}